@@ -0,0 +1,101 @@
+package w3w
+
+import (
+	"context"
+	"net/url"
+)
+
+// ----------------------------------------------------------------------------
+// Suggestion struct
+// ----------------------------------------------------------------------------
+
+// Suggestion holds a single candidate 3 word address returned from the autosuggest call
+type Suggestion struct {
+	Words    string `json:"words"`
+	Distance int    `json:"distance"`
+	Rank     int    `json:"rank"`
+	Country  string `json:"country"`
+}
+
+// suggestions holds the full autosuggest response from the server
+type suggestions struct {
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+// ----------------------------------------------------------------------------
+// AutoSuggest calls
+// ----------------------------------------------------------------------------
+
+// AutoSuggest returns candidate 3 word addresses for partial or misspelled input, e.g.
+// "index.home.r"
+func (w *W3W) AutoSuggest(input string, opts *Options) ([]Suggestion, error) {
+	return w.AutoSuggestContext(w.base, input, opts)
+}
+
+// AutoSuggestContext returns candidate 3 word addresses for partial or misspelled input, aborting
+// the call if ctx is cancelled
+func (w *W3W) AutoSuggestContext(ctx context.Context, input string, opts *Options) ([]Suggestion, error) {
+	vals := url.Values{}
+	vals.Set("key", w.apikey)
+	vals.Set("string", input)
+
+	if o := w.options(opts); o.InputLang != "" {
+		vals.Set("language", o.InputLang)
+		vals.Set("locale", o.lang())
+	}
+
+	s := &suggestions{[]Suggestion{}}
+	if err := w.exec(ctx, "/autosuggest", vals, opts, s); err != nil {
+		return nil, err
+	}
+
+	return s.Suggestions, nil
+}
+
+// ----------------------------------------------------------------------------
+// SuggestionIterator struct
+// ----------------------------------------------------------------------------
+
+// SuggestionIterator walks the results of an autosuggest call lazily, fetching them on the first
+// call to Next. The W3W service currently returns every suggestion in one response, so the iterator
+// walks that single page, but callers written against it keep working if paging is added.
+type SuggestionIterator struct {
+	fetch   func() ([]Suggestion, error)
+	page    []Suggestion
+	pos     int
+	fetched bool
+	err     error
+}
+
+// AutoSuggestIter returns an iterator over the candidate 3 word addresses for input. No request is
+// made until the first call to Next.
+func (w *W3W) AutoSuggestIter(ctx context.Context, input string, opts *Options) *SuggestionIterator {
+	return &SuggestionIterator{
+		fetch: func() ([]Suggestion, error) {
+			return w.AutoSuggestContext(ctx, input, opts)
+		},
+	}
+}
+
+// Next returns the next suggestion, or false once the suggestions are exhausted or the call failed.
+// Check Err after Next returns false.
+func (it *SuggestionIterator) Next() (*Suggestion, bool) {
+	if !it.fetched {
+		it.fetched = true
+		it.page, it.err = it.fetch()
+	}
+
+	if it.err != nil || it.pos >= len(it.page) {
+		return nil, false
+	}
+
+	s := &it.page[it.pos]
+	it.pos++
+
+	return s, true
+}
+
+// Err returns the error, if any, that stopped the iteration
+func (it *SuggestionIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,130 @@
+package w3w_test
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/devork/w3w"
+	"github.com/devork/w3w/w3wtest"
+)
+
+const suggestionsBody string = `{"suggestions":[{"words":"index.home.raft","distance":1,"rank":1,"country":"gb"}]}`
+
+// autoSuggestQuery makes an AutoSuggest call with opts against a w3wtest server and returns the
+// query it sent
+func autoSuggestQuery(t *testing.T, opts *w3w.Options, clientOpts ...w3w.Option) url.Values {
+	t.Helper()
+
+	s := w3wtest.NewServer(clientOpts...)
+	defer s.Close()
+
+	s.SetFixture("/autosuggest", w3wtest.Fixture{Status: 200, Body: suggestionsBody})
+	queries := recordQueries(s)
+
+	if _, err := s.W3W.AutoSuggest("index.home.r", opts); err != nil {
+		t.Fatal(err)
+	}
+
+	return queries()[0]
+}
+
+func TestAutoSuggestFocus(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       *w3w.Options
+		clientOpts []w3w.Option
+		want       string
+	}{
+		{"no focus", &w3w.Options{}, nil, ""},
+		{"focus", &w3w.Options{Focus: &w3w.LatLng{51.484463, -0.195405}}, nil, "51.484463,-0.195405"},
+		{
+			"full precision",
+			&w3w.Options{Focus: &w3w.LatLng{51.48446312345, -0.19540512345}},
+			[]w3w.Option{w3w.WithCoordinatePrecision(11)},
+			"51.48446312345,-0.19540512345",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := autoSuggestQuery(t, tt.opts, tt.clientOpts...)
+
+			if _, ok := q["focus"]; ok != (tt.want != "") || q.Get("focus") != tt.want {
+				t.Errorf("focus = %q (sent %t), want %q", q.Get("focus"), ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestAutoSuggestInputLang(t *testing.T) {
+	tests := []struct {
+		name string
+		opts *w3w.Options
+		want map[string]string
+	}{
+		{"lang only", &w3w.Options{Lang: "de"}, map[string]string{"lang": "de", "language": "", "locale": ""}},
+		{"input lang", &w3w.Options{Lang: "de", InputLang: "fr"}, map[string]string{"lang": "de", "language": "fr", "locale": "de"}},
+		{"input lang with default lang", &w3w.Options{InputLang: "fr"}, map[string]string{"lang": "en", "language": "fr", "locale": "en"}},
+		{"locale", &w3w.Options{Lang: "zh", Locale: "zh_tr"}, map[string]string{"lang": "zh", "language": "", "locale": "zh_tr"}},
+		{"locale overrides lang", &w3w.Options{Lang: "zh", InputLang: "fr", Locale: "zh_tr"}, map[string]string{"lang": "zh", "language": "fr", "locale": "zh_tr"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := autoSuggestQuery(t, tt.opts)
+
+			for param, want := range tt.want {
+				if got := q.Get(param); got != want {
+					t.Errorf("%s = %q, want %q", param, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAutoSuggestClipToCircle(t *testing.T) {
+	tests := []struct {
+		name   string
+		circle *w3w.Circle
+		want   string
+	}{
+		{"whole km", &w3w.Circle{Center: &w3w.LatLng{51.484463, -0.195405}, RadiusKm: 10}, "51.484463,-0.195405,10"},
+		{"fractional km", &w3w.Circle{Center: &w3w.LatLng{51.484463, -0.195405}, RadiusKm: 2.5}, "51.484463,-0.195405,2.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := autoSuggestQuery(t, &w3w.Options{ClipToCircle: tt.circle})
+
+			if got := q.Get("clip-to-circle"); got != tt.want {
+				t.Errorf("clip-to-circle = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAutoSuggestClipToCircleInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		circle *w3w.Circle
+	}{
+		{"no center", &w3w.Circle{RadiusKm: 10}},
+		{"invalid center", &w3w.Circle{Center: &w3w.LatLng{91, 0}, RadiusKm: 10}},
+		{"zero radius", &w3w.Circle{Center: &w3w.LatLng{51.484463, -0.195405}}},
+		{"negative radius", &w3w.Circle{Center: &w3w.LatLng{51.484463, -0.195405}, RadiusKm: -1}},
+	}
+
+	s := w3wtest.NewServer()
+	defer s.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.W3W.AutoSuggest("index.home.r", &w3w.Options{ClipToCircle: tt.circle})
+
+			if !errors.Is(err, w3w.ErrInvalidOptions) {
+				t.Errorf("AutoSuggest() error = %v, want ErrInvalidOptions", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package w3w
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultBatchConcurrency is the number of requests a batch call keeps in flight by default
+	defaultBatchConcurrency int = 4
+)
+
+// ----------------------------------------------------------------------------
+// Batch calls
+// ----------------------------------------------------------------------------
+
+// WithBatchConcurrency sets how many requests a batch call, such as WordsBatch, keeps in flight at
+// once. Values below 1 keep the default of 4.
+func WithBatchConcurrency(n int) Option {
+	return optionFunc(func(w *W3W) error {
+		if n > 0 {
+			w.batchSize = n
+		}
+		return nil
+	})
+}
+
+// WithBatchItemTimeout gives each call made by a batch method, such as WordsBatch or Neighbours, its
+// own deadline of d, so a hung entry fails with context.DeadlineExceeded while the rest carry on.
+// The batch's context still applies to every entry. It works alongside WithTimeout, which limits
+// each call in the same way, so whichever duration is shorter wins. The difference is that
+// WithTimeout also applies to single calls and this does not. A zero d means no per-entry deadline.
+func WithBatchItemTimeout(d time.Duration) Option {
+	return optionFunc(func(w *W3W) error {
+		w.itemTimeout = d
+		return nil
+	})
+}
+
+// WordsResult is the outcome of converting one entry of a WordsBatch
+type WordsResult struct {
+	Input    What3Words
+	Position *Position
+	Err      error
+}
+
+// Invalid reports whether the entry was rejected by the client's own checks before any request was
+// made, so retrying it unchanged can't succeed. Errors from the service are a *StatusError or
+// *APIError instead.
+func (r WordsResult) Invalid() bool {
+	return invalid(r.Err)
+}
+
+// PositionResult is the outcome of converting one entry of a PositionBatch
+type PositionResult struct {
+	Input    LatLng
+	Position *Position
+	Err      error
+}
+
+// Invalid reports whether the entry was rejected by the client's own checks before any request was
+// made, as for WordsResult
+func (r PositionResult) Invalid() bool {
+	return invalid(r.Err)
+}
+
+// invalid reports whether err is one of the validation errors returned before a request is made
+func invalid(err error) bool {
+	return errors.Is(err, ErrInvalidWords) || errors.Is(err, ErrInvalidLatLng) ||
+		errors.Is(err, ErrInvalidOptions) || errors.Is(err, ErrUnsupportedLanguage)
+}
+
+// WordsBatch converts each of the 3 word strings in batch to a LatLng position, running the calls
+// concurrently. The results are in the same order as batch, each holding its input and either the
+// position or the error, so the failures can be picked out and retried. Once ctx is cancelled,
+// in-flight calls are aborted and any entries not yet started fail with ctx.Err().
+func (w *W3W) WordsBatch(ctx context.Context, batch []What3Words, opts *Options) []WordsResult {
+	results := make([]WordsResult, len(batch))
+
+	w.runBatch(ctx, len(batch), func(ctx context.Context, i int) {
+		pos, err := w.WordsContext(ctx, batch[i], opts)
+		results[i] = WordsResult{batch[i], pos, err}
+	})
+
+	return results
+}
+
+// PositionBatch converts each LatLng position in points to a 3 word string, running the calls
+// concurrently. As with WordsBatch, the results are in the same order as points and cancelling ctx
+// aborts the remaining calls.
+func (w *W3W) PositionBatch(ctx context.Context, points []LatLng, opts *Options) []PositionResult {
+	results := make([]PositionResult, len(points))
+
+	w.runBatch(ctx, len(points), func(ctx context.Context, i int) {
+		pos, err := w.PositionContext(ctx, points[i], opts)
+		results[i] = PositionResult{points[i], pos, err}
+	})
+
+	return results
+}
+
+// WordsMultiLang converts the LatLng position to its 3 words in each of langs, running the calls
+// concurrently, and returns them keyed by language code. If any call fails the first error, in the
+// order of langs, is returned.
+func (w *W3W) WordsMultiLang(ll LatLng, langs []string, opts *Options) (map[string]What3Words, error) {
+	return w.WordsMultiLangContext(w.base, ll, langs, opts)
+}
+
+// WordsMultiLangContext converts the LatLng position to its 3 words in each of langs, aborting the
+// calls if ctx is cancelled
+func (w *W3W) WordsMultiLangContext(ctx context.Context, ll LatLng, langs []string, opts *Options) (map[string]What3Words, error) {
+	words := make([]What3Words, len(langs))
+	errs := make([]error, len(langs))
+
+	w.runBatch(ctx, len(langs), func(ctx context.Context, i int) {
+		o := w.options(opts).clone()
+		o.Lang = langs[i]
+
+		pos, err := w.PositionContext(ctx, ll, o)
+		if err != nil {
+			errs[i] = err
+			return
+		}
+		words[i] = pos.Words
+	})
+
+	byLang := make(map[string]What3Words, len(langs))
+	for i, lang := range langs {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		byLang[lang] = words[i]
+	}
+
+	return byLang, nil
+}
+
+// runBatch calls fn for each index in [0, n) using the client's batch concurrency, returning once
+// every call has finished. Each call gets a context derived from ctx with the client's per-entry
+// timeout, if set. Indexes that haven't started when ctx is cancelled are still passed to fn so it
+// can record ctx.Err() in order.
+func (w *W3W) runBatch(ctx context.Context, n int, fn func(ctx context.Context, i int)) {
+	workers := w.batchSize
+	if workers > n {
+		workers = n
+	}
+
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				w.runItem(ctx, j, fn)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+
+	close(jobs)
+	wg.Wait()
+}
+
+// runItem calls fn for index i under the client's per-entry timeout
+func (w *W3W) runItem(ctx context.Context, i int, fn func(ctx context.Context, i int)) {
+	if w.itemTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.itemTimeout)
+		defer cancel()
+	}
+
+	fn(ctx, i)
+}
@@ -0,0 +1,151 @@
+package w3w_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/devork/w3w"
+	"github.com/devork/w3w/w3wtest"
+)
+
+// BenchmarkWordsBatch compares batch throughput through the default client's tuned transport with
+// http.DefaultTransport, whose pool of 2 idle connections per host makes most of a concurrent
+// batch's requests dial afresh
+func BenchmarkWordsBatch(b *testing.B) {
+	transports := []struct {
+		name string
+		opts []w3w.Option
+	}{
+		{"tuned", nil},
+		{"default transport", []w3w.Option{w3w.WithClient(&http.Client{Transport: http.DefaultTransport.(*http.Transport).Clone()})}},
+	}
+
+	batch := make([]w3w.What3Words, 64)
+	for i := range batch {
+		batch[i] = w3w.What3Words{"prom", "cape", "pump"}
+	}
+
+	for _, tt := range transports {
+		b.Run(tt.name, func(b *testing.B) {
+			s := w3wtest.NewServer(append(tt.opts, w3w.WithBatchConcurrency(16))...)
+			defer s.Close()
+
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				for _, r := range s.W3W.WordsBatch(context.Background(), batch, nil) {
+					if r.Err != nil {
+						b.Fatal(r.Err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBatchItemTimeout(t *testing.T) {
+	const slow = "slow.slow.slow"
+
+	s := w3wtest.NewServer(w3w.WithBatchItemTimeout(50*time.Millisecond), w3w.WithBatchConcurrency(4))
+	defer s.Close()
+
+	// Stall the slow entry until the client gives up on it, or the test ends
+	release := make(chan struct{})
+	defer close(release)
+
+	next := s.Config.Handler
+	s.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("string") == slow {
+			select {
+			case <-r.Context().Done():
+			case <-release:
+			}
+			return
+		}
+
+		next.ServeHTTP(rw, r)
+	})
+
+	batch := []w3w.What3Words{
+		{"prom", "cape", "pump"},
+		{"slow", "slow", "slow"},
+		{"index", "home", "raft"},
+		{"prom", "cape", "pump"},
+	}
+
+	// The batch's own deadline only stops a missing per-entry timeout from hanging the test
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	results := s.W3W.WordsBatch(ctx, batch, nil)
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("WordsBatch() took %v, want the slow entry cut off after 50ms", elapsed)
+	}
+
+	for i, r := range results {
+		if r.Input != batch[i] {
+			t.Errorf("results[%d].Input = %v, want %v", i, r.Input, batch[i])
+		}
+
+		if r.Input.String() == slow {
+			if !errors.Is(r.Err, context.DeadlineExceeded) || r.Position != nil {
+				t.Errorf("results[%d] = %v, %v, want no position and context.DeadlineExceeded", i, r.Position, r.Err)
+			}
+			continue
+		}
+
+		if r.Err != nil || r.Position == nil {
+			t.Errorf("results[%d] = %v, %v, want a position", i, r.Position, r.Err)
+		}
+	}
+}
+
+func TestWordsMultiLangContext(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		wantErr  error
+		wantReqs int
+	}{
+		{"background", context.Background(), nil, 3},
+		{"cancelled", cancelled, context.Canceled, 0},
+	}
+
+	langs := []string{"de", "en", "fr"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := w3wtest.NewServer()
+			defer s.Close()
+
+			queries := recordQueries(s)
+
+			byLang, err := s.W3W.WordsMultiLangContext(tt.ctx, w3w.LatLng{51.484463, -0.195405}, langs, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("WordsMultiLangContext() = %v, want %v", err, tt.wantErr)
+			}
+
+			if n := len(queries()); n != tt.wantReqs {
+				t.Errorf("made %d requests, want %d", n, tt.wantReqs)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			for _, lang := range langs {
+				if got := byLang[lang]; got != (w3w.What3Words{"prom", "cape", "pump"}) {
+					t.Errorf("words for %s = %v, want prom.cape.pump", lang, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+package w3w
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// Language cache
+// ----------------------------------------------------------------------------
+
+// langCache holds get-languages responses in memory for a fixed TTL. A nil *langCache is a valid,
+// disabled cache.
+type langCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]langEntry
+}
+
+type langEntry struct {
+	langs   *Languages
+	expires time.Time
+}
+
+func newLangCache(ttl time.Duration) *langCache {
+	return &langCache{ttl: ttl, entries: map[string]langEntry{}}
+}
+
+// langCacheKey builds the cache key for a call from its query params, excluding the API key
+func langCacheKey(vals url.Values, opts *Options, prec int) string {
+	q := opts.query(vals, prec)
+	q.Del("key")
+
+	return q.Encode()
+}
+
+// get returns a copy of the cached languages for key if present and not expired
+func (c *langCache) get(key string) (*Languages, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	if time.Now().After(e.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	return e.langs.copy(), true
+}
+
+// put stores a copy of langs under key
+func (c *langCache) put(key string, langs *Languages) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = langEntry{langs.copy(), time.Now().Add(c.ttl)}
+}
+
+// copy returns a Languages that shares no state with l, so cached values can't be mutated by callers
+func (l *Languages) copy() *Languages {
+	return &Languages{append([]Language{}, l.Languages...)}
+}
+
+// ----------------------------------------------------------------------------
+// Language validation
+// ----------------------------------------------------------------------------
+
+// langValidator checks requested languages against the list of supported languages, which is
+// fetched on first use and then kept for the life of the client
+type langValidator struct {
+	mu    sync.Mutex
+	codes []string
+
+	// fetching is closed when the fetch in flight finishes, and is nil when there's none
+	fetching chan struct{}
+}
+
+// WithLanguageValidation checks the language of each call against the languages supported by the
+// W3W service, failing with ErrUnsupportedLanguage before making the call if it isn't one of them.
+// The supported list costs one extra request, made on first use.
+func WithLanguageValidation() Option {
+	return optionFunc(func(w *W3W) error {
+		w.langCheck = &langValidator{}
+		return nil
+	})
+}
+
+// check returns an error wrapping ErrUnsupportedLanguage if lang is not a supported language code.
+// An empty lang falls back to the API default and always passes.
+func (v *langValidator) check(ctx context.Context, w *W3W, lang string) error {
+	if v == nil || lang == "" {
+		return nil
+	}
+
+	codes, err := v.supported(ctx, w)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range codes {
+		if strings.EqualFold(c, lang) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %q, expected one of %s", ErrUnsupportedLanguage, lang, strings.Join(codes, ", "))
+}
+
+// supported returns the supported language codes, fetching them if this is the first use or an
+// earlier fetch failed. Only one fetch is made at a time, and other callers wait for it until their
+// ctx is done.
+func (v *langValidator) supported(ctx context.Context, w *W3W) ([]string, error) {
+	for {
+		v.mu.Lock()
+
+		if v.codes != nil {
+			codes := v.codes
+			v.mu.Unlock()
+			return codes, nil
+		}
+
+		if v.fetching == nil {
+			done := make(chan struct{})
+			v.fetching = done
+			v.mu.Unlock()
+
+			codes, err := v.fetch(ctx, w)
+
+			v.mu.Lock()
+			if err == nil {
+				v.codes = codes
+			}
+			v.fetching = nil
+			v.mu.Unlock()
+
+			close(done)
+			return codes, err
+		}
+
+		fetching := v.fetching
+		v.mu.Unlock()
+
+		select {
+		case <-fetching:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// fetch gets the supported language codes from the service. It's made while the call being checked
+// holds its concurrency slot, so it's observed like any other call but doesn't take a slot itself.
+func (v *langValidator) fetch(ctx context.Context, w *W3W) ([]string, error) {
+	vals := url.Values{}
+	vals.Set("key", w.apikey)
+
+	path, in := "/get-languages", interface{}(&Languages{[]Language{}})
+	if w.version == APIv3 {
+		path, in = "/v3/available-languages", &v3Languages{}
+	}
+
+	err := w.observe(ctx, path, vals, func(ctx context.Context) error {
+		return w.attempt(ctx, w.buildURL(path, vals), nil, in)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if v3, ok := in.(*v3Languages); ok {
+		return v3.languages().Codes(), nil
+	}
+
+	return in.(*Languages).Codes(), nil
+}
+
+// ----------------------------------------------------------------------------
+// Response cache
+// ----------------------------------------------------------------------------
+
+// lruCache holds up to size Words and Position results, evicting the least recently used. A nil
+// *lruCache is a valid, disabled cache.
+type lruCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	items map[string]*list.Element
+}
+
+type lruEntry struct {
+	key string
+	pos *Position
+}
+
+// WithCache memoizes up to size Words and Position results in memory, keyed on the normalized input,
+// language and corners, so repeated lookups skip the HTTP call. A call can bypass the cache with
+// Options.NoCache. A size below 1 disables the cache.
+func WithCache(size int) Option {
+	return optionFunc(func(w *W3W) error {
+		if size < 1 {
+			w.cache = nil
+			return nil
+		}
+
+		w.cache = &lruCache{size: size, order: list.New(), items: map[string]*list.Element{}}
+		return nil
+	})
+}
+
+func (c *lruCache) get(key string) (*Position, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	c.order.MoveToFront(e)
+	return e.Value.(*lruEntry).pos.copy(), true
+}
+
+func (c *lruCache) put(key string, pos *Position) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		e.Value.(*lruEntry).pos = pos.copy()
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&lruEntry{key, pos.copy()})
+
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// cached returns the cached result for a call of kind with the normalized input, or calls fetch and
+// caches its result. The cache is skipped if it's disabled or opts sets NoCache. Invalid options are
+// rejected before the lookup, so a cache hit can't hide them.
+func (w *W3W) cached(kind, input string, opts *Options, fetch func() (*Position, error)) (*Position, error) {
+	o := w.options(opts)
+
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+
+	if w.cache == nil || o.NoCache {
+		return fetch()
+	}
+
+	key := fmt.Sprintf("%s|%s|%s|%s|%t", kind, input, o.lang(), o.Locale, o.Corners)
+
+	if pos, ok := w.cache.get(key); ok {
+		return pos, nil
+	}
+
+	pos, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	w.cache.put(key, pos)
+	return pos, nil
+}
+
+// copy returns a Position that shares no state with p, so cached values can't be mutated by callers
+func (p *Position) copy() *Position {
+	c := *p
+
+	if p.Position != nil {
+		ll := *p.Position
+		c.Position = &ll
+	}
+
+	if p.Corners != nil {
+		c.Corners = &BBox{}
+		for i, ll := range p.Corners {
+			if ll != nil {
+				corner := *ll
+				c.Corners[i] = &corner
+			}
+		}
+	}
+
+	return &c
+}
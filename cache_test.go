@@ -0,0 +1,141 @@
+package w3w_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devork/w3w"
+	"github.com/devork/w3w/w3wtest"
+)
+
+// recordMetrics is a w3w.Metrics recording the endpoint of each request
+type recordMetrics struct {
+	mu        sync.Mutex
+	endpoints []string
+}
+
+func (m *recordMetrics) IncRequest(endpoint string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.endpoints = append(m.endpoints, endpoint)
+}
+
+func (m *recordMetrics) IncError(category string)                        {}
+func (m *recordMetrics) ObserveLatency(endpoint string, d time.Duration) {}
+
+func TestLanguageValidationFetch(t *testing.T) {
+	m := &recordMetrics{}
+
+	// A single slot catches the fetch trying to take a second one while the call holds the first
+	s := w3wtest.NewServer(w3w.WithLanguageValidation(), w3w.WithMaxConcurrency(1), w3w.WithMetrics(m))
+	defer s.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if _, err := s.W3W.WordsContext(ctx, w3w.What3Words{"prom", "cape", "pump"}, &w3w.Options{Lang: "de"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"/w3w", "/get-languages"}
+	if len(m.endpoints) != len(want) || m.endpoints[0] != want[0] || m.endpoints[1] != want[1] {
+		t.Errorf("requests = %v, want %v", m.endpoints, want)
+	}
+}
+
+func TestLanguageValidationWait(t *testing.T) {
+	s := w3wtest.NewServer(w3w.WithLanguageValidation())
+	defer s.Close()
+
+	// Stall the languages fetch until the test ends
+	arrived := make(chan struct{}, 1)
+	release := make(chan struct{})
+	defer close(release)
+
+	next := s.Config.Handler
+	s.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/get-languages" {
+			arrived <- struct{}{}
+			<-release
+		}
+
+		next.ServeHTTP(rw, r)
+	})
+
+	queries := recordQueries(s)
+	words := w3w.What3Words{"prom", "cape", "pump"}
+
+	go s.W3W.Words(words, nil)
+	<-arrived
+
+	// A second call waits for the fetch in flight rather than making its own, but only until its ctx
+	// is done
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := s.W3W.WordsContext(ctx, words, nil)
+		errc <- err
+	}()
+
+	select {
+	case err := <-errc:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("WordsContext() = %v, want context.DeadlineExceeded", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("WordsContext() still waiting after 1s, want it to give up after 50ms")
+	}
+
+	if n := len(queries()); n != 1 {
+		t.Errorf("made %d requests, want only the stalled fetch", n)
+	}
+}
+
+func TestCacheValidatesOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts *w3w.Options
+	}{
+		{"corners and no corners", &w3w.Options{Corners: true, NoCorners: true}},
+		{"cache and no cache", &w3w.Options{Corners: true, NoCache: true, UseCache: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := w3wtest.NewServer(w3w.WithCache(8))
+			defer s.Close()
+
+			queries := recordQueries(s)
+			words := w3w.What3Words{"prom", "cape", "pump"}
+			ll := w3w.LatLng{51.484463, -0.195405}
+
+			// Prime the cache with the entries the invalid options would otherwise hit
+			if _, err := s.W3W.Words(words, &w3w.Options{Corners: true}); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := s.W3W.Position(ll, &w3w.Options{Corners: true}); err != nil {
+				t.Fatal(err)
+			}
+
+			if pos, err := s.W3W.Words(words, tt.opts); !errors.Is(err, w3w.ErrInvalidOptions) || pos != nil {
+				t.Errorf("Words() = %v, %v, want ErrInvalidOptions", pos, err)
+			}
+
+			if pos, err := s.W3W.Position(ll, tt.opts); !errors.Is(err, w3w.ErrInvalidOptions) || pos != nil {
+				t.Errorf("Position() = %v, %v, want ErrInvalidOptions", pos, err)
+			}
+
+			if n := len(queries()); n != 2 {
+				t.Errorf("made %d requests, want only the 2 priming the cache", n)
+			}
+		})
+	}
+}
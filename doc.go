@@ -20,10 +20,17 @@ import "bitbucket.org/devork/w3w"
 ### Create a new `w3w` struct:
 
 ```type=golang
-w, err := w3w.New("APIKEY", &w3w.Options{"en", true})
+w, err := w3w.New("APIKEY", &w3w.Options{Lang: "en", Corners: true})
+```
+The new call allows a default set of options to be included with each call to the API. Any field
+set in the options given to a call overrides the matching default. if not provided, the default of
+`lang=en` is used, or the language given by `WithLanguage`.
+
+The client itself can be configured with further options, such as `WithClient` and `WithEndpoint`:
+
+```type=golang
+w, err := w3w.New("APIKEY", w3w.WithDefaults(&w3w.Options{Lang: "de"}), w3w.WithTimeout(5*time.Second))
 ```
-The new call allows a default set of options to be included with each call to the API. These can be
-overridden on each call. if not provided, the defaults of `lang=en` and `corners=true` are used.
 
 ### Fetch the position of a W3W
 
@@ -43,11 +50,25 @@ pos, err = w.Position(w3w.LatLng{51.484463, -0.195405}, nil)
 langs, err := w.LangsW3W(w3w.What3Words{"index", "home", "raft"}, nil)
 ```
 
+### Cancel a call with a context
+
+```type=golang
+ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+defer cancel()
+pos, err := w.WordsContext(ctx, w3w.What3Words{"prom", "cape", "pump"}, nil)
+```
+
+Each call has a `Context` variant (`WordsContext`, `PositionContext`, `LangsW3WContext` and
+`LangsPosContext`) which aborts the underlying HTTP request when the context is cancelled.
+
 ### Override the defaults
 
 ```type=golang
-opts := &w3w.Options{"de", false}
+opts := &w3w.Options{Lang: "de", NoCorners: true}
 pos, err = w.Position(w3w.LatLng{51.484463, -0.195405}, opts)
 ```
+
+Fields left unset keep the client default, so this keeps any other defaults but asks for German words
+without the corners the defaults above request.
 */
 package w3w
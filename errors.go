@@ -0,0 +1,236 @@
+package w3w
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultRetryAfter is the delay reported for a 429 response without a Retry-After header
+	defaultRetryAfter time.Duration = 10 * time.Second
+)
+
+// ----------------------------------------------------------------------------
+// StatusError struct
+// ----------------------------------------------------------------------------
+
+// StatusError is returned when the W3W service responds with a non-2xx HTTP status. The raw
+// response body is kept so callers can log what the server said, and if the body held an API
+// error payload it is decoded into API.
+type StatusError struct {
+	StatusCode int
+	Body       string
+	API        *APIError
+
+	// URL is the request URL, with the API key redacted, to help diagnose parameter encoding
+	URL string
+
+	// RetryAfter is how long the server asked to wait before retrying a 429 response, from the
+	// Retry-After header, or a default of 10 seconds if the header is absent
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	if e.API != nil {
+		return fmt.Sprintf("w3w: unexpected HTTP status %d: %s: %s", e.StatusCode, e.API.Code, e.API.Message)
+	}
+
+	if e.Body == "" {
+		return fmt.Sprintf("w3w: unexpected HTTP status %d", e.StatusCode)
+	}
+
+	return fmt.Sprintf("w3w: unexpected HTTP status %d: %s", e.StatusCode, e.Body)
+}
+
+// Is reports a 401 or 403 response as ErrUnauthorized, so a revoked or invalid key can be detected
+// with `errors.Is(err, ErrUnauthorized)`, and a 429 response as ErrRateLimited
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+
+	return false
+}
+
+// Unwrap exposes the decoded API error payload, if the response carried one, to errors.As
+func (e *StatusError) Unwrap() error {
+	if e.API == nil {
+		return nil
+	}
+
+	return e.API
+}
+
+// newStatusError builds the StatusError for a non-2xx response with the given body
+func newStatusError(resp *http.Response, body []byte) *StatusError {
+	e := &StatusError{
+		StatusCode: resp.StatusCode,
+		Body:       strings.TrimSpace(string(body)),
+		API:        parseAPIError(body),
+	}
+
+	if resp.Request != nil && resp.Request.URL != nil {
+		e.URL = redactKey(resp.Request.URL.String())
+	}
+
+	if e.StatusCode == http.StatusTooManyRequests {
+		e.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+	}
+
+	return e
+}
+
+// parseRetryAfter parses a Retry-After header given either as a number of seconds or as an HTTP
+// date, falling back to defaultRetryAfter
+func parseRetryAfter(v string) time.Duration {
+	if secs, err := strconv.Atoi(strings.TrimSpace(v)); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+		return 0
+	}
+
+	return defaultRetryAfter
+}
+
+// ----------------------------------------------------------------------------
+// APIError struct
+// ----------------------------------------------------------------------------
+
+// APIError holds the structured error payload returned by the W3W service, e.g.
+// `{"error":{"code":"BadWords","message":"..."}}`
+type APIError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("w3w: %s: %s", e.Code, e.Message)
+}
+
+// errorPayload is the envelope the W3W service wraps an APIError in
+type errorPayload struct {
+	Error *APIError `json:"error"`
+}
+
+// parseAPIError extracts an APIError from a response body, returning nil if the body does not
+// hold one
+func parseAPIError(body []byte) *APIError {
+	var p errorPayload
+
+	if err := json.Unmarshal(body, &p); err != nil || p.Error == nil {
+		return nil
+	}
+
+	if p.Error.Code == "" && p.Error.Message == "" {
+		return nil
+	}
+
+	return p.Error
+}
+
+// ----------------------------------------------------------------------------
+// ErrorCategory type
+// ----------------------------------------------------------------------------
+
+// ErrorCategory is a broad classification of an error returned by this package, e.g. for choosing a
+// CLI exit code
+type ErrorCategory int
+
+// Error categories returned by Category
+const (
+	// CategoryNone is the category of a nil error
+	CategoryNone ErrorCategory = iota
+
+	// CategoryUnknown is an error that doesn't fit any other category
+	CategoryUnknown
+
+	// CategoryUser is an error in the input, such as bad words, coordinates or options
+	CategoryUser
+
+	// CategoryAuth is a missing, invalid or revoked API key
+	CategoryAuth
+
+	// CategoryTransient is a network, timeout, rate limit or server error that may succeed on retry
+	CategoryTransient
+)
+
+func (c ErrorCategory) String() string {
+	switch c {
+	case CategoryNone:
+		return "none"
+	case CategoryUser:
+		return "user"
+	case CategoryAuth:
+		return "auth"
+	case CategoryTransient:
+		return "transient"
+	}
+
+	return "unknown"
+}
+
+// authCodes are the APIError codes that mean the API key is at fault
+var authCodes = map[string]bool{
+	"InvalidKey":    true,
+	"MissingKey":    true,
+	"SuspendedKey":  true,
+	"InvalidApiKey": true,
+	"MissingApiKey": true,
+}
+
+// Category classifies err by inspecting the typed and sentinel errors it wraps
+func Category(err error) ErrorCategory {
+	if err == nil {
+		return CategoryNone
+	}
+
+	switch {
+	case errors.Is(err, ErrNoAPIKey), errors.Is(err, ErrUnauthorized):
+		return CategoryAuth
+	case errors.Is(err, ErrRateLimited), errors.Is(err, context.DeadlineExceeded):
+		return CategoryTransient
+	case invalid(err):
+		return CategoryUser
+	}
+
+	var se *StatusError
+	if errors.As(err, &se) {
+		if se.StatusCode >= 500 {
+			return CategoryTransient
+		}
+		if se.API != nil && authCodes[se.API.Code] {
+			return CategoryAuth
+		}
+		return CategoryUser
+	}
+
+	var ae *APIError
+	if errors.As(err, &ae) {
+		if authCodes[ae.Code] {
+			return CategoryAuth
+		}
+		return CategoryUser
+	}
+
+	var ne net.Error
+	if errors.As(err, &ne) {
+		return CategoryTransient
+	}
+
+	return CategoryUnknown
+}
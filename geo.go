@@ -0,0 +1,164 @@
+package w3w
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// earthRadius is the mean radius of the earth in metres, as used by the Haversine formula
+	earthRadius float64 = 6371008.8
+)
+
+// ----------------------------------------------------------------------------
+// LatLng geometry
+// ----------------------------------------------------------------------------
+
+// DistanceTo returns the great-circle distance in metres between ll and other, calculated with the
+// Haversine formula
+func (ll *LatLng) DistanceTo(other *LatLng) float64 {
+	lat1 := radians(ll.Lat())
+	lat2 := radians(other.Lat())
+	dLat := lat2 - lat1
+	dLng := radians(other.Lng() - ll.Lng())
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// Antipode returns the point on the opposite side of the earth to ll, with the latitude negated and
+// the longitude moved 180 degrees, kept within [-180, 180]
+func (ll *LatLng) Antipode() *LatLng {
+	// 0 - lat rather than -lat, so the equator stays at 0 rather than -0
+	return &LatLng{0 - ll.Lat(), wrapLng(ll.Lng() + 180)}
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// DMS formats ll in degrees, minutes and seconds, rounded to the nearest second, with the hemisphere
+// in place of the sign, e.g. `51°30'26"N 0°7'39"W`. The equator and prime meridian are N and E.
+func (ll *LatLng) DMS() string {
+	return dms(ll.Lat(), "N", "S") + " " + dms(ll.Lng(), "E", "W")
+}
+
+// dms formats deg as degrees, minutes and seconds followed by pos or neg by its sign. Rounding is
+// done on the total seconds so that 59.5" carries into the minutes rather than printing 60".
+func dms(deg float64, pos, neg string) string {
+	hemi := pos
+	if deg < 0 {
+		hemi = neg
+	}
+
+	secs := int64(math.Round(math.Abs(deg) * 3600))
+	if secs == 0 {
+		hemi = pos
+	}
+
+	return fmt.Sprintf("%d°%d'%d\"%s", secs/3600, secs%3600/60, secs%60, hemi)
+}
+
+// ----------------------------------------------------------------------------
+// Position geometry
+// ----------------------------------------------------------------------------
+
+// WithinMeters reports whether the coordinates of p and other are no more than meters apart. It is
+// false if either position has no coordinates.
+func (p *Position) WithinMeters(other *Position, meters float64) bool {
+	if p == nil || other == nil || p.Position == nil || other.Position == nil {
+		return false
+	}
+
+	return p.Position.DistanceTo(other.Position) <= meters
+}
+
+// ----------------------------------------------------------------------------
+// BBox geometry
+// ----------------------------------------------------------------------------
+
+// Center returns the midpoint of the square, or nil if the box or either of its corners is missing.
+// For a box spanning the date line the midpoint is taken the short way across it.
+func (b *BBox) Center() *LatLng {
+	if b == nil || b.SW() == nil || b.NE() == nil {
+		return nil
+	}
+
+	sw, ne := b.SW(), b.NE()
+
+	return &LatLng{(sw.Lat() + ne.Lat()) / 2, wrapLng(sw.Lng() + b.lngSpan()/2)}
+}
+
+// lngSpan returns the degrees of longitude from the SW to the NE corner, going east across the date
+// line if the NE corner's longitude is the smaller
+func (b *BBox) lngSpan() float64 {
+	dLng := b.NE().Lng() - b.SW().Lng()
+	if dLng < 0 {
+		dLng += 360
+	}
+
+	return dLng
+}
+
+// Polygon returns the corners of the square as a closed, counter-clockwise ring of five points: SW,
+// SE, NE, NW and SW again. It returns nil if the box or either of its corners is missing.
+func (b *BBox) Polygon() []*LatLng {
+	if b == nil || b.SW() == nil || b.NE() == nil {
+		return nil
+	}
+
+	sw, ne := b.SW(), b.NE()
+
+	return []*LatLng{
+		{sw.Lat(), sw.Lng()},
+		{sw.Lat(), ne.Lng()},
+		{ne.Lat(), ne.Lng()},
+		{ne.Lat(), sw.Lng()},
+		{sw.Lat(), sw.Lng()},
+	}
+}
+
+// WidthMeters returns the east-west size of the square in metres, measured at its middle latitude. A
+// box spanning the date line is measured the short way across it. Missing corners give 0.
+func (b *BBox) WidthMeters() float64 {
+	if b == nil || b.SW() == nil || b.NE() == nil {
+		return 0
+	}
+
+	midLat := clampLat((b.SW().Lat() + b.NE().Lat()) / 2)
+
+	return earthRadius * radians(b.lngSpan()) * math.Max(0, math.Cos(radians(midLat)))
+}
+
+// HeightMeters returns the north-south size of the square in metres. Latitudes beyond the poles are
+// clamped. Missing corners give 0.
+func (b *BBox) HeightMeters() float64 {
+	if b == nil || b.SW() == nil || b.NE() == nil {
+		return 0
+	}
+
+	return earthRadius * radians(math.Abs(clampLat(b.NE().Lat())-clampLat(b.SW().Lat())))
+}
+
+// AreaSquareMeters returns the approximate area of the square in square metres
+func (b *BBox) AreaSquareMeters() float64 {
+	return b.WidthMeters() * b.HeightMeters()
+}
+
+func clampLat(lat float64) float64 {
+	return math.Max(-90, math.Min(90, lat))
+}
+
+// wrapLng brings a longitude stepped across the antimeridian back into [-180, 180]
+func wrapLng(lng float64) float64 {
+	switch {
+	case lng > 180:
+		return lng - 360
+	case lng < -180:
+		return lng + 360
+	}
+
+	return lng
+}
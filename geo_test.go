@@ -0,0 +1,153 @@
+package w3w_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/devork/w3w"
+)
+
+func TestDistanceTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to w3w.LatLng
+		want     float64
+	}{
+		{"same point", w3w.LatLng{51.5074, -0.1278}, w3w.LatLng{51.5074, -0.1278}, 0},
+		{"london to paris", w3w.LatLng{51.5074, -0.1278}, w3w.LatLng{48.8566, 2.3522}, 343556},
+		{"half the equator", w3w.LatLng{0, 0}, w3w.LatLng{0, 180}, 20015115},
+		{"pole to pole", w3w.LatLng{90, 0}, w3w.LatLng{-90, 0}, 20015115},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.from.DistanceTo(&tt.to); math.Abs(got-tt.want) > 1 {
+				t.Errorf("DistanceTo() = %.0f, want %.0f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBBoxCenter(t *testing.T) {
+	tests := []struct {
+		name string
+		box  *w3w.BBox
+		want *w3w.LatLng
+	}{
+		{"nil box", nil, nil},
+		{"missing corner", &w3w.BBox{{51.484449, -0.195426}, nil}, nil},
+		{"square", &w3w.BBox{{51.484449, -0.195426}, {51.484476, -0.195383}}, &w3w.LatLng{51.4844625, -0.1954045}},
+		{"date line", &w3w.BBox{{10, 179.99999}, {10.00002, -179.99999}}, &w3w.LatLng{10.00001, 180}},
+		{"west of date line", &w3w.BBox{{10, 179.99997}, {10.00002, -179.99999}}, &w3w.LatLng{10.00001, 179.99999}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.box.Center()
+
+			switch {
+			case got == nil || tt.want == nil:
+				if got != tt.want {
+					t.Errorf("Center() = %v, want %v", got, tt.want)
+				}
+			case math.Abs(got.Lat()-tt.want.Lat()) > 1e-9 || math.Abs(got.Lng()-tt.want.Lng()) > 1e-9:
+				t.Errorf("Center() = %v, want %v", *got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestBBoxPolygon(t *testing.T) {
+	tests := []struct {
+		name string
+		box  *w3w.BBox
+	}{
+		{"square", &w3w.BBox{{51.484449, -0.195426}, {51.484476, -0.195383}}},
+		{"southern hemisphere", &w3w.BBox{{-33.868834, 151.209281}, {-33.868807, 151.209313}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ring := tt.box.Polygon()
+
+			if len(ring) != 5 {
+				t.Fatalf("Polygon() has %d points, want 5", len(ring))
+			}
+
+			if *ring[0] != *ring[4] {
+				t.Errorf("Polygon() isn't closed, starts at %v and ends at %v", *ring[0], *ring[4])
+			}
+
+			if *ring[0] != *tt.box.SW() || *ring[2] != *tt.box.NE() {
+				t.Errorf("Polygon() = %v, want SW first and NE third", ring)
+			}
+
+			// The shoelace formula, with lng as x and lat as y, is positive for a counter-clockwise ring
+			var area float64
+			for i := 0; i < len(ring)-1; i++ {
+				area += ring[i].Lng()*ring[i+1].Lat() - ring[i+1].Lng()*ring[i].Lat()
+			}
+
+			if area <= 0 {
+				t.Errorf("Polygon() is wound clockwise, signed area %g", area)
+			}
+		})
+	}
+
+	if ring := (&w3w.BBox{{51.484449, -0.195426}, nil}).Polygon(); ring != nil {
+		t.Errorf("Polygon() of a box missing a corner = %v, want nil", ring)
+	}
+}
+
+func TestDMS(t *testing.T) {
+	tests := []struct {
+		name string
+		ll   w3w.LatLng
+		want string
+	}{
+		{"london", w3w.LatLng{51.507222, -0.1275}, `51°30'26"N 0°7'39"W`},
+		{"sydney", w3w.LatLng{-33.8688, 151.2093}, `33°52'8"S 151°12'33"E`},
+		{"origin", w3w.LatLng{0, 0}, `0°0'0"N 0°0'0"E`},
+		{"rounds to zero", w3w.LatLng{-0.0001, -0.0001}, `0°0'0"N 0°0'0"E`},
+		{"seconds carry", w3w.LatLng{10.99999, -20.99999}, `11°0'0"N 21°0'0"W`},
+		{"poles and date line", w3w.LatLng{-90, 180}, `90°0'0"S 180°0'0"E`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ll.DMS(); got != tt.want {
+				t.Errorf("DMS() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAntipode(t *testing.T) {
+	tests := []struct {
+		name string
+		ll   w3w.LatLng
+		want w3w.LatLng
+	}{
+		{"origin", w3w.LatLng{0, 0}, w3w.LatLng{0, 180}},
+		{"equator west", w3w.LatLng{0, -90}, w3w.LatLng{0, 90}},
+		{"london", w3w.LatLng{51.5, -0.1}, w3w.LatLng{-51.5, 179.9}},
+		{"north pole", w3w.LatLng{90, 10}, w3w.LatLng{-90, -170}},
+		{"south pole", w3w.LatLng{-90, 0}, w3w.LatLng{90, 180}},
+		{"date line west", w3w.LatLng{10, -180}, w3w.LatLng{-10, 0}},
+		{"date line east", w3w.LatLng{10, 180}, w3w.LatLng{-10, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.ll.Antipode()
+
+			if math.Abs(got.Lat()-tt.want.Lat()) > 1e-9 || math.Abs(got.Lng()-tt.want.Lng()) > 1e-9 {
+				t.Errorf("Antipode() = %v, want %v", *got, tt.want)
+			}
+
+			if math.Signbit(got.Lat()) && got.Lat() == 0 {
+				t.Errorf("Antipode() lat = -0, want 0")
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package w3w
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// ----------------------------------------------------------------------------
+// GeoJSON types
+// ----------------------------------------------------------------------------
+
+type geoJSONFeature struct {
+	Type       string            `json:"type"`
+	Geometry   geoJSONGeometry   `json:"geometry"`
+	Properties geoJSONProperties `json:"properties"`
+}
+
+type geoJSONGeometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+type geoJSONProperties struct {
+	Words    string `json:"words"`
+	Language string `json:"language,omitempty"`
+}
+
+// geoJSONPoint returns ll in GeoJSON's [lng, lat] order
+func geoJSONPoint(ll *LatLng) [2]float64 {
+	return [2]float64{ll.Lng(), ll.Lat()}
+}
+
+// GeoJSON returns the position as a GeoJSON Feature with the words and language as properties. If the
+// corners of the square are known the geometry is the square as a Polygon, otherwise it is the
+// position as a Point.
+func (p *Position) GeoJSON() ([]byte, error) {
+	f := geoJSONFeature{
+		Type:       "Feature",
+		Properties: geoJSONProperties{p.Words.String(), p.Language},
+	}
+
+	switch ring := p.Corners.Polygon(); {
+	case ring != nil:
+		coords := make([][2]float64, len(ring))
+		for i, ll := range ring {
+			coords[i] = geoJSONPoint(ll)
+		}
+		f.Geometry = geoJSONGeometry{"Polygon", [][][2]float64{coords}}
+	case p.Position != nil:
+		f.Geometry = geoJSONGeometry{"Point", geoJSONPoint(p.Position)}
+	default:
+		return nil, errors.New("w3w: position has no coordinates")
+	}
+
+	return json.Marshal(f)
+}
@@ -0,0 +1,71 @@
+package w3w
+
+import (
+	"context"
+	"errors"
+	"net/url"
+)
+
+// ----------------------------------------------------------------------------
+// Grid types
+// ----------------------------------------------------------------------------
+
+// Line is a single line segment of the W3W grid, held as its start and end positions
+type Line [2]*LatLng
+
+// Start returns the start position of the line
+func (l *Line) Start() *LatLng {
+	return l[0]
+}
+
+// End returns the end position of the line
+func (l *Line) End() *LatLng {
+	return l[1]
+}
+
+// Grid holds the lines of the 3 metre grid within a bounding box
+type Grid struct {
+	Lines []Line
+}
+
+// gridResponse holds the full grid response from the server
+type gridResponse struct {
+	Lines []struct {
+		Start *LatLng `json:"start"`
+		End   *LatLng `json:"end"`
+	} `json:"lines"`
+}
+
+// ----------------------------------------------------------------------------
+// Grid calls
+// ----------------------------------------------------------------------------
+
+// Grid obtains the lines of the 3 metre grid within the given bounding box. If the box is too large
+// the server responds with an error, returned as an *APIError.
+func (w *W3W) Grid(bbox BBox, opts *Options) (*Grid, error) {
+	return w.GridContext(w.base, bbox, opts)
+}
+
+// GridContext obtains the lines of the 3 metre grid within the given bounding box, aborting the call
+// if ctx is cancelled
+func (w *W3W) GridContext(ctx context.Context, bbox BBox, opts *Options) (*Grid, error) {
+	if bbox.SW() == nil || bbox.NE() == nil {
+		return nil, errors.New("w3w: grid bounding box requires both corners")
+	}
+
+	vals := url.Values{}
+	vals.Set("key", w.apikey)
+	vals.Set("bbox", formatLatLng(*bbox.SW(), w.precision)+","+formatLatLng(*bbox.NE(), w.precision))
+
+	resp := &gridResponse{}
+	if err := w.exec(ctx, "/grid", vals, opts, resp); err != nil {
+		return nil, err
+	}
+
+	grid := &Grid{make([]Line, 0, len(resp.Lines))}
+	for _, l := range resp.Lines {
+		grid.Lines = append(grid.Lines, Line{l.Start, l.End})
+	}
+
+	return grid, nil
+}
@@ -0,0 +1,60 @@
+package w3w
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// RequestLogger interface
+// ----------------------------------------------------------------------------
+
+// RequestLogger is notified of each HTTP request made to the W3W service and of its outcome. The API
+// key is always redacted from the logged URL.
+type RequestLogger interface {
+	// LogRequest is called before each request is sent
+	LogRequest(method, url string)
+
+	// LogResponse is called once the response headers arrive, or with a status of 0 if the request
+	// failed without a response
+	LogResponse(status int, dur time.Duration)
+}
+
+// nopLogger is the default RequestLogger, which discards everything
+type nopLogger struct{}
+
+func (nopLogger) LogRequest(method, url string)             {}
+func (nopLogger) LogResponse(status int, dur time.Duration) {}
+
+// WithLogger sends details of each request and response to l. A nil l disables logging.
+func WithLogger(l RequestLogger) Option {
+	return optionFunc(func(w *W3W) error {
+		if l == nil {
+			l = nopLogger{}
+		}
+		w.logger = l
+		return nil
+	})
+}
+
+// keyParam matches the value of the key query param in a URL
+var keyParam = regexp.MustCompile(`([?&]key=)[^&#]*`)
+
+// redactKey replaces the value of the key query param in u with REDACTED, so the API key never
+// appears in logs or error messages. It works on the raw string so even a URL that fails to parse is
+// redacted.
+func redactKey(u string) string {
+	return keyParam.ReplaceAllString(u, "${1}REDACTED")
+}
+
+// redactErr redacts the API key from the URL embedded in err, if it holds a *url.Error
+func redactErr(err error) error {
+	var ue *url.Error
+	if errors.As(err, &ue) {
+		ue.URL = redactKey(ue.URL)
+	}
+
+	return err
+}
@@ -0,0 +1,93 @@
+package w3w
+
+import (
+	"expvar"
+	"time"
+)
+
+// latencyBuckets are the upper bounds of the latency histogram kept by ExpvarMetrics
+var latencyBuckets = []time.Duration{
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+}
+
+// ----------------------------------------------------------------------------
+// Metrics interface
+// ----------------------------------------------------------------------------
+
+// Metrics receives counts and timings for each call made to the W3W service. The endpoint is the
+// API path, e.g. "/position", and the category is the String of the error's Category.
+type Metrics interface {
+	IncRequest(endpoint string)
+	IncError(category string)
+	ObserveLatency(endpoint string, d time.Duration)
+}
+
+// nopMetrics is the default Metrics, which discards everything
+type nopMetrics struct{}
+
+func (nopMetrics) IncRequest(endpoint string)                      {}
+func (nopMetrics) IncError(category string)                        {}
+func (nopMetrics) ObserveLatency(endpoint string, d time.Duration) {}
+
+// WithMetrics reports the requests, errors and latency of each call to m. A nil m disables metrics.
+func WithMetrics(m Metrics) Option {
+	return optionFunc(func(w *W3W) error {
+		if m == nil {
+			m = nopMetrics{}
+		}
+		w.metrics = m
+		return nil
+	})
+}
+
+// ----------------------------------------------------------------------------
+// ExpvarMetrics struct
+// ----------------------------------------------------------------------------
+
+// ExpvarMetrics is a Metrics that publishes its counters with the expvar package, so they appear on
+// the /debug/vars page
+type ExpvarMetrics struct {
+	requests *expvar.Map
+	errors   *expvar.Map
+	latency  *expvar.Map
+}
+
+// NewExpvarMetrics publishes the expvar maps name.requests (by endpoint), name.errors (by
+// category) and name.latency (a histogram by endpoint and upper bound, e.g. "/position:le_250ms").
+// As with expvar.Publish, it panics if name is already in use, so call it once per name.
+func NewExpvarMetrics(name string) *ExpvarMetrics {
+	return &ExpvarMetrics{
+		requests: expvar.NewMap(name + ".requests"),
+		errors:   expvar.NewMap(name + ".errors"),
+		latency:  expvar.NewMap(name + ".latency"),
+	}
+}
+
+// IncRequest counts a call to endpoint
+func (m *ExpvarMetrics) IncRequest(endpoint string) {
+	m.requests.Add(endpoint, 1)
+}
+
+// IncError counts an error in category
+func (m *ExpvarMetrics) IncError(category string) {
+	m.errors.Add(category, 1)
+}
+
+// ObserveLatency counts a call to endpoint that took d in the first histogram bucket d fits in
+func (m *ExpvarMetrics) ObserveLatency(endpoint string, d time.Duration) {
+	for _, b := range latencyBuckets {
+		if d <= b {
+			m.latency.Add(endpoint+":le_"+b.String(), 1)
+			return
+		}
+	}
+
+	m.latency.Add(endpoint+":le_inf", 1)
+}
@@ -0,0 +1,83 @@
+package w3w
+
+import (
+	"context"
+)
+
+// Direction names a side of a square, as the key of the squares returned by Neighbours
+type Direction string
+
+// The directions of the neighbouring squares
+const (
+	North Direction = "N"
+	South Direction = "S"
+	East  Direction = "E"
+	West  Direction = "W"
+)
+
+// directions is the order the neighbours are looked up and their errors reported in
+var directions = [...]Direction{North, South, East, West}
+
+// ----------------------------------------------------------------------------
+// Neighbouring squares
+// ----------------------------------------------------------------------------
+
+// Neighbours returns the squares to the north, south, east and west of pos, keyed by direction. The
+// centre of each is found by stepping one square's height or width from the centre of pos, and the
+// four are resolved concurrently. If pos has no corners they are requested from its coordinates
+// first.
+//
+// if pos is nil or has neither corners nor coordinates, the returned error is `ErrNoCoordinates`. If
+// any lookup fails the first error, in the order N, S, E, W, is returned.
+func (w *W3W) Neighbours(pos *Position, opts *Options) (map[Direction]*Position, error) {
+	return w.NeighboursContext(w.base, pos, opts)
+}
+
+// NeighboursContext returns the squares to the north, south, east and west of pos, aborting the
+// calls if ctx is cancelled
+func (w *W3W) NeighboursContext(ctx context.Context, pos *Position, opts *Options) (map[Direction]*Position, error) {
+	if pos == nil {
+		return nil, ErrNoCoordinates
+	}
+
+	corners := pos.Corners
+
+	if corners.Center() == nil {
+		if pos.Position == nil {
+			return nil, ErrNoCoordinates
+		}
+
+		var err error
+		if corners, err = w.SquareContext(ctx, *pos.Position, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	centre := corners.Center()
+	dLat := corners.NE().Lat() - corners.SW().Lat()
+	dLng := corners.lngSpan()
+
+	steps := map[Direction]LatLng{
+		North: {clampLat(centre.Lat() + dLat), centre.Lng()},
+		South: {clampLat(centre.Lat() - dLat), centre.Lng()},
+		East:  {centre.Lat(), wrapLng(centre.Lng() + dLng)},
+		West:  {centre.Lat(), wrapLng(centre.Lng() - dLng)},
+	}
+
+	squares := make([]*Position, len(directions))
+	errs := make([]error, len(directions))
+
+	w.runBatch(ctx, len(directions), func(ctx context.Context, i int) {
+		squares[i], errs[i] = w.PositionContext(ctx, steps[directions[i]], opts)
+	})
+
+	byDirection := make(map[Direction]*Position, len(directions))
+	for i, dir := range directions {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		byDirection[dir] = squares[i]
+	}
+
+	return byDirection, nil
+}
@@ -0,0 +1,110 @@
+package w3w_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/devork/w3w"
+	"github.com/devork/w3w/w3wtest"
+)
+
+func TestNeighbours(t *testing.T) {
+	tests := []struct {
+		name    string
+		corners *w3w.BBox
+		want    map[string]bool
+	}{
+		{
+			"square",
+			&w3w.BBox{{51.48444, -0.19543}, {51.48447, -0.19539}},
+			map[string]bool{
+				"51.484485,-0.195410": true,
+				"51.484425,-0.195410": true,
+				"51.484455,-0.195370": true,
+				"51.484455,-0.195450": true,
+			},
+		},
+		{
+			"date line",
+			&w3w.BBox{{10, 179.99999}, {10.00002, -179.99999}},
+			map[string]bool{
+				"10.000030,180.000000":  true,
+				"9.999990,180.000000":   true,
+				"10.000010,-179.999980": true,
+				"10.000010,179.999980":  true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := w3wtest.NewServer()
+			defer s.Close()
+
+			queries := recordQueries(s)
+
+			squares, err := s.W3W.Neighbours(&w3w.Position{Corners: tt.corners}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(squares) != 4 {
+				t.Errorf("Neighbours() returned %d squares, want 4", len(squares))
+			}
+
+			got := map[string]bool{}
+			for _, q := range queries() {
+				got[q.Get("position")] = true
+			}
+
+			for pos := range tt.want {
+				if !got[pos] {
+					t.Errorf("Neighbours() didn't look up %s, looked up %v", pos, got)
+				}
+			}
+		})
+	}
+}
+
+func TestNeighboursRequestsCorners(t *testing.T) {
+	s := w3wtest.NewServer()
+	defer s.Close()
+
+	queries := recordQueries(s)
+
+	if _, err := s.W3W.Neighbours(&w3w.Position{Position: &w3w.LatLng{51.484463, -0.195405}}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if q := queries(); len(q) != 5 || q[0].Get("corners") != "true" {
+		t.Errorf("Neighbours() made requests %v, want a corners lookup then 4 more", q)
+	}
+}
+
+func TestNeighboursNoCoordinates(t *testing.T) {
+	tests := []struct {
+		name string
+		pos  *w3w.Position
+	}{
+		{"nil position", nil},
+		{"empty position", &w3w.Position{}},
+		{"missing corner", &w3w.Position{Corners: &w3w.BBox{{51.48444, -0.19543}, nil}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := w3wtest.NewServer()
+			defer s.Close()
+
+			queries := recordQueries(s)
+
+			if got, err := s.W3W.Neighbours(tt.pos, nil); !errors.Is(err, w3w.ErrNoCoordinates) || got != nil {
+				t.Errorf("Neighbours() = %v, %v, want ErrNoCoordinates", got, err)
+			}
+
+			if n := len(queries()); n != 0 {
+				t.Errorf("made %d requests, want none", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package w3w
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// Option interface
+// ----------------------------------------------------------------------------
+
+// Option configures a W3W client when passed to New
+type Option interface {
+	apply(*W3W) error
+}
+
+// optionFunc adapts a plain function to the Option interface
+type optionFunc func(*W3W) error
+
+func (f optionFunc) apply(w *W3W) error {
+	return f(w)
+}
+
+// apply allows an *Options to be passed straight to New as the client defaults, keeping the
+// original `New(key, &Options{...})` form working. The client keeps its own copy, so changing o
+// afterwards doesn't affect it. A nil *Options keeps the package defaults.
+func (o *Options) apply(w *W3W) error {
+	if o != nil {
+		w.defaults = o.clone()
+	}
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// Client options
+// ----------------------------------------------------------------------------
+
+// WithDefaults sets the options associated with each W3W call that is made with nil options. The
+// client keeps its own copy of defaults. A nil defaults keeps the package defaults. If defaults has
+// no Lang, the language given by WithLanguage is used, or `lang=en` without it.
+func WithDefaults(defaults *Options) Option {
+	return defaults
+}
+
+// WithLanguage sets the language requested by calls whose options and client defaults don't give
+// one, in place of "en", e.g. "fr" for a deployment serving France. An empty lang keeps "en".
+func WithLanguage(lang string) Option {
+	return optionFunc(func(w *W3W) error {
+		if lang == "" {
+			lang = defaultLang
+		}
+
+		w.lang = lang
+		return nil
+	})
+}
+
+// WithClient uses the given HTTP client for all calls to the W3W service, allowing timeouts,
+// transports, proxies and TLS settings to be configured. A nil client keeps the default, whose
+// transport is a copy of http.DefaultTransport that attempts HTTP/2 and keeps up to 32 idle
+// connections per host for 90 seconds.
+func WithClient(c *http.Client) Option {
+	return optionFunc(func(w *W3W) error {
+		if c != nil {
+			w.client = c
+		}
+		return nil
+	})
+}
+
+// WithEndpoint points the client at a different base URL than the public W3W service, e.g. a mock
+// server in tests or an on-premise deployment. The URL must be absolute; any trailing slash is
+// stripped so the API paths join cleanly.
+func WithEndpoint(baseURL string) Option {
+	return optionFunc(func(w *W3W) error {
+		u, err := url.Parse(strings.TrimSpace(baseURL))
+
+		if err != nil {
+			return fmt.Errorf("w3w: invalid endpoint %q: %v", baseURL, err)
+		}
+
+		if u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("w3w: invalid endpoint %q: must be an absolute URL", baseURL)
+		}
+
+		w.endpoint = strings.TrimRight(u.String(), "/")
+		return nil
+	})
+}
+
+// WithLanguageCacheTTL caches the available languages responses in memory for d, so repeated calls
+// within the TTL are served without an HTTP round trip. A zero or negative d disables the cache.
+func WithLanguageCacheTTL(d time.Duration) Option {
+	return optionFunc(func(w *W3W) error {
+		if d > 0 {
+			w.langs = newLangCache(d)
+		} else {
+			w.langs = nil
+		}
+		return nil
+	})
+}
+
+// WithUserAgent overrides the default "devork-w3w-go/<version>" User-Agent header sent on every
+// request. An empty ua keeps the default.
+func WithUserAgent(ua string) Option {
+	return optionFunc(func(w *W3W) error {
+		if ua != "" {
+			w.ua = ua
+		}
+		return nil
+	})
+}
+
+// WithCoordinatePrecision sets the number of decimal places coordinates are sent to the W3W
+// service with. The default of 6 places resolves to about 0.1m, well within a 3m square; n must be
+// between 5 (about 1m) and 15.
+func WithCoordinatePrecision(n int) Option {
+	return optionFunc(func(w *W3W) error {
+		if n < minPrecision || n > maxPrecision {
+			return fmt.Errorf("w3w: coordinate precision %d must be between %d and %d", n, minPrecision, maxPrecision)
+		}
+
+		w.precision = n
+		return nil
+	})
+}
+
+// WithHeader adds a header sent on every request, such as an API gateway token. It may be given
+// more than once, and repeating a key adds another value. Headers given here are applied after the
+// defaults, so they replace the User-Agent and Accept-Language, but never Accept or Accept-Encoding
+// as the response must still be JSON this client can decode.
+func WithHeader(key, value string) Option {
+	return optionFunc(func(w *W3W) error {
+		if w.headers == nil {
+			w.headers = http.Header{}
+		}
+		w.headers.Add(key, value)
+		return nil
+	})
+}
+
+// WithMaxResponseSize sets the most bytes of a response body, after decompression, that are read
+// before a call fails with ErrResponseTooLarge. The default of 4MB suits every call except perhaps a
+// Grid over a wide bounding box, which may need more.
+func WithMaxResponseSize(n int64) Option {
+	return optionFunc(func(w *W3W) error {
+		if n <= 0 {
+			return fmt.Errorf("w3w: max response size %d must be positive", n)
+		}
+
+		w.maxBody = n
+		return nil
+	})
+}
+
+// WithBaseContext sets the context that calls made without one, such as Words rather than
+// WordsContext, run under. Cancelling ctx, e.g. on service shutdown, aborts those calls. A context
+// passed explicitly to a Context method takes precedence and is used instead of ctx.
+func WithBaseContext(ctx context.Context) Option {
+	return optionFunc(func(w *W3W) error {
+		if ctx == nil {
+			return errors.New("w3w: base context must not be nil")
+		}
+
+		w.base = ctx
+		return nil
+	})
+}
+
+// WithInsecureSkipVerify disables TLS certificate verification on the default HTTP client, for
+// testing against a local proxy with a self-signed certificate. A warning is logged when it takes
+// effect. It is ignored if a client is given with WithClient. Never use it in production.
+func WithInsecureSkipVerify() Option {
+	return optionFunc(func(w *W3W) error {
+		w.insecure = true
+		return nil
+	})
+}
+
+// defaultTransport returns the transport of the default client, a copy of http.DefaultTransport with
+// a larger idle connection pool. The clone keeps its ForceAttemptHTTP2, so HTTP/2 is used wherever
+// the server supports it.
+func defaultTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	t.IdleConnTimeout = defaultIdleConnTimeout
+
+	return t
+}
+
+// insecureTransport returns a copy of the default transport that skips certificate verification
+func insecureTransport() *http.Transport {
+	t := defaultTransport()
+	t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+
+	return t
+}
+
+// WithAutoNormalize normalizes the words passed to Words and WordsContext before they're checked, so
+// pasted input with stray whitespace, like What3Words{"Index", " Home ", "Raft"}, succeeds rather
+// than returning ErrInvalidWords. By default words are checked as given.
+func WithAutoNormalize() Option {
+	return optionFunc(func(w *W3W) error {
+		w.normalize = true
+		return nil
+	})
+}
+
+// WithTimeout applies a timeout of d to each call, covering every retry attempt. It layers on top of
+// any deadline on the context passed to a call, so whichever is sooner applies. A call that exceeds
+// the timeout returns an error wrapping context.DeadlineExceeded. A zero d means no additional
+// deadline.
+func WithTimeout(d time.Duration) Option {
+	return optionFunc(func(w *W3W) error {
+		w.timeout = d
+		return nil
+	})
+}
@@ -0,0 +1,110 @@
+package w3w
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// ----------------------------------------------------------------------------
+// RateLimit struct
+// ----------------------------------------------------------------------------
+
+// RateLimit holds the rate limit details reported by the W3W service in the headers of the last
+// response. A value of -1 means the header was absent.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     int64
+}
+
+// parseRateLimit extracts the X-RateLimit-* headers from h
+func parseRateLimit(h http.Header) RateLimit {
+	return RateLimit{
+		Limit:     int(headerInt(h, "X-RateLimit-Limit")),
+		Remaining: int(headerInt(h, "X-RateLimit-Remaining")),
+		Reset:     headerInt(h, "X-RateLimit-Reset"),
+	}
+}
+
+func headerInt(h http.Header, name string) int64 {
+	n, err := strconv.ParseInt(h.Get(name), 10, 64)
+	if err != nil {
+		return -1
+	}
+
+	return n
+}
+
+// rateLimits records the RateLimit from the most recent response, safe for concurrent use
+type rateLimits struct {
+	mu   sync.Mutex
+	last RateLimit
+}
+
+func newRateLimits() *rateLimits {
+	return &rateLimits{last: RateLimit{-1, -1, -1}}
+}
+
+func (r *rateLimits) update(h http.Header) {
+	rl := parseRateLimit(h)
+
+	r.mu.Lock()
+	r.last = rl
+	r.mu.Unlock()
+}
+
+func (r *rateLimits) get() RateLimit {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.last
+}
+
+// RateLimit returns the rate limit details from the most recent response received by the client,
+// so callers can back off before being throttled
+func (w *W3W) RateLimit() RateLimit {
+	return w.rate.get()
+}
+
+// ----------------------------------------------------------------------------
+// Concurrency limit
+// ----------------------------------------------------------------------------
+
+// WithMaxConcurrency caps the calls in flight at once across the whole client, single and batch
+// alike, at n, so bursts stay under the service's rate limit. The cap is shared with clones of the
+// client. A call waiting for a slot gives up with ctx.Err() if its context is done first. Values
+// below 1 remove the cap.
+func WithMaxConcurrency(n int) Option {
+	return optionFunc(func(w *W3W) error {
+		if n < 1 {
+			w.sem = nil
+			return nil
+		}
+
+		w.sem = make(chan struct{}, n)
+		return nil
+	})
+}
+
+// acquire waits for a slot under the concurrency cap, if there is one, or for ctx to be done
+func (w *W3W) acquire(ctx context.Context) error {
+	if w.sem == nil {
+		return nil
+	}
+
+	select {
+	case w.sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees the slot taken by acquire
+func (w *W3W) release() {
+	if w.sem != nil {
+		<-w.sem
+	}
+}
@@ -0,0 +1,151 @@
+package w3w
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+// Recording and replay
+// ----------------------------------------------------------------------------
+
+// recording is an interaction saved by WithRecorder, one JSON file per request
+type recording struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   string      `json:"body"`
+}
+
+// WithRecorder saves each request the client makes, and the response to it, as a JSON file in dir,
+// which is created if needed. The API key is masked in the saved URL. A later client built with
+// WithReplay on the same dir serves the responses without touching the network, e.g. for
+// deterministic tests that use no API quota. Recording wraps the transport of the client's HTTP
+// client, whether the default or one given with WithClient.
+func WithRecorder(dir string) Option {
+	return optionFunc(func(w *W3W) error {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("w3w: can't create recording dir: %w", err)
+		}
+
+		w.recordDir, w.replayDir = dir, ""
+		return nil
+	})
+}
+
+// WithReplay serves every request from the recordings WithRecorder saved in dir, matched on the
+// method and the URL with the API key masked, instead of calling the service. A request with no
+// recording fails with an error naming its masked URL.
+func WithReplay(dir string) Option {
+	return optionFunc(func(w *W3W) error {
+		w.recordDir, w.replayDir = "", dir
+		return nil
+	})
+}
+
+// recordTransport records or replays the requests sent through it, depending on which of record and
+// replay is set. The default transport is used to record if next is nil.
+type recordTransport struct {
+	next   http.RoundTripper
+	record string
+	replay string
+}
+
+// wrapClient returns a copy of c whose transport records or replays requests as set by WithRecorder
+// and WithReplay, or c itself if neither was used
+func (w *W3W) wrapClient(c *http.Client) *http.Client {
+	if w.recordDir == "" && w.replayDir == "" {
+		return c
+	}
+
+	wrapped := *c
+	wrapped.Transport = &recordTransport{next: c.Transport, record: w.recordDir, replay: w.replayDir}
+
+	return &wrapped
+}
+
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	masked := redactKey(req.URL.String())
+
+	if t.replay != "" {
+		return t.load(req, masked)
+	}
+
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	// Leave compression to the transport, so the body it returns and records is plain text
+	out := req.Clone(req.Context())
+	out.Header.Del("Accept-Encoding")
+
+	resp, err := next.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = ioutil.NopCloser(strings.NewReader(string(body)))
+
+	// Keep the & in URLs readable rather than escaped as \u0026
+	var data bytes.Buffer
+	enc := json.NewEncoder(&data)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(recording{req.Method, masked, resp.StatusCode, resp.Header, string(body)}); err != nil {
+		return nil, err
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(t.record, recordingName(req.Method, masked)), data.Bytes(), 0644); err != nil {
+		return nil, fmt.Errorf("w3w: can't save recording: %w", err)
+	}
+
+	return resp, nil
+}
+
+// load returns the recorded response to req, whose masked URL is masked
+func (t *recordTransport) load(req *http.Request, masked string) (*http.Response, error) {
+	data, err := ioutil.ReadFile(filepath.Join(t.replay, recordingName(req.Method, masked)))
+	if err != nil {
+		return nil, fmt.Errorf("w3w: no recording of %s %s in %s", req.Method, masked, t.replay)
+	}
+
+	var rec recording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("w3w: invalid recording of %s %s: %w", req.Method, masked, err)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.Status, http.StatusText(rec.Status)),
+		StatusCode:    rec.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        rec.Header,
+		Body:          ioutil.NopCloser(strings.NewReader(rec.Body)),
+		ContentLength: int64(len(rec.Body)),
+		Request:       req,
+	}, nil
+}
+
+// recordingName returns the file name of the recording of a request, a hash of its method and
+// masked URL so any URL makes a safe name
+func recordingName(method, masked string) string {
+	return fmt.Sprintf("%x.json", sha256.Sum256([]byte(method+" "+masked)))
+}
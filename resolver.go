@@ -0,0 +1,107 @@
+package w3w
+
+import (
+	"context"
+	"sync"
+)
+
+// ----------------------------------------------------------------------------
+// Resolver struct
+// ----------------------------------------------------------------------------
+
+// Resolver keeps the words and coordinates of an address editor in step: FromWords resolves typed
+// words to a position and FromPosition resolves a dragged pin to words, both with the same options.
+//
+// The result of the last successful lookup is kept, and returned without a call to the service if
+// the next lookup has the same input: the same words once normalized, or the same coordinates at the
+// client's coordinate precision. Any other input, in either direction, replaces it. A failed lookup
+// leaves it in place, and Reset discards it. A Resolver is safe for concurrent use.
+type Resolver struct {
+	w    *W3W
+	opts *Options
+
+	mu   sync.Mutex
+	key  string
+	last *Position
+}
+
+// NewResolver returns a Resolver making its lookups through w with opts. The resolver keeps its own
+// copy of opts; nil uses the client's defaults.
+func NewResolver(w *W3W, opts *Options) *Resolver {
+	r := &Resolver{w: w}
+	if opts != nil {
+		r.opts = opts.clone()
+	}
+
+	return r
+}
+
+// FromWords returns the position of words, reusing the last result if the words haven't changed
+func (r *Resolver) FromWords(words What3Words) (*Position, error) {
+	return r.FromWordsContext(r.w.base, words)
+}
+
+// FromWordsContext returns the position of words, aborting the call if ctx is cancelled
+func (r *Resolver) FromWordsContext(ctx context.Context, words What3Words) (*Position, error) {
+	return r.resolve("words|"+words.Normalize().String(), func() (*Position, error) {
+		return r.w.WordsContext(ctx, words, r.opts)
+	})
+}
+
+// FromPosition returns the words of the square ll falls in, reusing the last result if ll hasn't
+// changed
+func (r *Resolver) FromPosition(ll LatLng) (*Position, error) {
+	return r.FromPositionContext(r.w.base, ll)
+}
+
+// FromPositionContext returns the words of the square ll falls in, aborting the call if ctx is
+// cancelled
+func (r *Resolver) FromPositionContext(ctx context.Context, ll LatLng) (*Position, error) {
+	return r.resolve("position|"+formatLatLng(ll, r.w.precision), func() (*Position, error) {
+		return r.w.PositionContext(ctx, ll, r.opts)
+	})
+}
+
+// Last returns the result of the last successful lookup, or nil if there hasn't been one since the
+// resolver was created or reset
+func (r *Resolver) Last() *Position {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.last == nil {
+		return nil
+	}
+
+	return r.last.copy()
+}
+
+// Reset discards the last result, so the next lookup always calls the service
+func (r *Resolver) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.key, r.last = "", nil
+}
+
+// resolve returns the last result if it was for key, otherwise it calls fetch and keeps its result.
+// The lock isn't held during fetch, so a slow call doesn't block Last or Reset.
+func (r *Resolver) resolve(key string, fetch func() (*Position, error)) (*Position, error) {
+	r.mu.Lock()
+	if r.last != nil && r.key == key {
+		pos := r.last.copy()
+		r.mu.Unlock()
+		return pos, nil
+	}
+	r.mu.Unlock()
+
+	pos, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.key, r.last = key, pos.copy()
+	r.mu.Unlock()
+
+	return pos, nil
+}
@@ -0,0 +1,71 @@
+package w3w
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// Retry policy
+// ----------------------------------------------------------------------------
+
+// retryPolicy controls how many times exec attempts a call and how long it waits between attempts
+type retryPolicy struct {
+	attempts int
+	base     time.Duration
+}
+
+// WithRetry retries calls that fail with a connection error or a retryable status (429, 500, 502,
+// 503 or 504) up to maxAttempts times in total, waiting an exponentially increasing, jittered delay
+// starting at base between attempts. Other responses, such as a 403 for a bad key, fail immediately.
+func WithRetry(maxAttempts int, base time.Duration) Option {
+	return optionFunc(func(w *W3W) error {
+		if maxAttempts < 1 {
+			maxAttempts = 1
+		}
+		w.retry = retryPolicy{maxAttempts, base}
+		return nil
+	})
+}
+
+// backoff returns the delay before the next attempt, after attempt attempts have failed. Half the
+// delay is fixed and half is random so concurrent clients don't retry in lockstep.
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	d := p.base << uint(attempt-1)
+	if d <= 0 {
+		return 0
+	}
+
+	half := d / 2
+
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+// wait sleeps for the backoff after attempt, returning early with ctx.Err() if ctx is cancelled
+func (p retryPolicy) wait(ctx context.Context, attempt int) error {
+	t := time.NewTimer(p.backoff(attempt))
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// retryableStatus reports whether an HTTP status is worth retrying
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,86 @@
+package w3w
+
+import (
+	"context"
+	"errors"
+	"net/url"
+)
+
+// ----------------------------------------------------------------------------
+// Tracer interface
+// ----------------------------------------------------------------------------
+
+// Tracer creates a client span around each call to the W3W service. It is deliberately small so this
+// package doesn't depend on OpenTelemetry; an adapter around an OpenTelemetry trace.Tracer is a few
+// lines:
+//
+//	type otelTracer struct{ t trace.Tracer }
+//
+//	func (o otelTracer) Start(ctx context.Context, name string) (context.Context, w3w.Span) {
+//		ctx, s := o.t.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+//		return ctx, otelSpan{s}
+//	}
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a single traced call, as created by a Tracer
+type Span interface {
+	// SetAttribute records a key/value attribute on the span
+	SetAttribute(key string, value interface{})
+
+	// SetError records err and sets the span status to error
+	SetError(err error)
+
+	// End completes the span
+	End()
+}
+
+// WithTracer wraps each call in a span created by t, named after the endpoint path, e.g.
+// "w3w /position". A nil t disables tracing.
+func WithTracer(t Tracer) Option {
+	return optionFunc(func(w *W3W) error {
+		w.tracer = t
+		return nil
+	})
+}
+
+// startSpan starts a span for a call to path with the query params vals, if the client has a
+// tracer. The API key is never recorded. The returned finish func must be called with the outcome
+// of the call.
+func (w *W3W) startSpan(ctx context.Context, path string, vals url.Values) (context.Context, func(error)) {
+	if w.tracer == nil {
+		return ctx, func(error) {}
+	}
+
+	ctx, span := w.tracer.Start(ctx, "w3w "+path)
+
+	if s := vals.Get("string"); s != "" {
+		span.SetAttribute("w3w.words", s)
+	}
+
+	if s := vals.Get("words"); s != "" {
+		span.SetAttribute("w3w.words", s)
+	}
+
+	if p := vals.Get("position"); p != "" {
+		span.SetAttribute("w3w.position", p)
+	}
+
+	if p := vals.Get("coordinates"); p != "" {
+		span.SetAttribute("w3w.position", p)
+	}
+
+	return ctx, func(err error) {
+		var se *StatusError
+		if errors.As(err, &se) {
+			span.SetAttribute("http.status_code", se.StatusCode)
+		}
+
+		if err != nil {
+			span.SetError(err)
+		}
+
+		span.End()
+	}
+}
@@ -0,0 +1,201 @@
+package w3w
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// Supported W3W API versions
+const (
+	// APIv1 is the legacy API, served from paths such as /w3w and /position
+	APIv1 string = "v1"
+
+	// APIv3 is the current API, served from paths such as /v3/convert-to-coordinates
+	APIv3 string = "v3"
+)
+
+// WithAPIVersion selects the version of the W3W API the client calls, either APIv1 (the default) or
+// APIv3. The calls map to the matching paths and response shapes for the version. With APIv3 the
+// languages calls, such as LangsPos and Languages, use available-languages, which lists every
+// language whatever the words, position or focus given.
+func WithAPIVersion(version string) Option {
+	return optionFunc(func(w *W3W) error {
+		switch version {
+		case APIv1, APIv3:
+			w.version = version
+			return nil
+		}
+
+		return fmt.Errorf("w3w: unsupported API version %q, expected %q or %q", version, APIv1, APIv3)
+	})
+}
+
+// ----------------------------------------------------------------------------
+// v3 response types
+// ----------------------------------------------------------------------------
+
+// v3Position holds the v3 response for a convert-to-coordinates or convert-to-3wa call
+type v3Position struct {
+	Square      *v3Square  `json:"square"`
+	Coordinates *LatLng    `json:"coordinates"`
+	Words       What3Words `json:"words"`
+	Language    string     `json:"language"`
+	Map         string     `json:"map"`
+
+	NearestPlace string `json:"nearestPlace"`
+	Country      string `json:"country"`
+}
+
+// v3Square holds the corners of the square, which v3 nests as objects rather than the legacy
+// corners array
+type v3Square struct {
+	Southwest *LatLng `json:"southwest"`
+	Northeast *LatLng `json:"northeast"`
+}
+
+// position converts the v3 response to the Position returned by the legacy API
+func (v *v3Position) position() *Position {
+	p := &Position{
+		Words:    v.Words,
+		Position: v.Coordinates,
+		Language: v.Language,
+		Map:      v.Map,
+
+		NearestPlace: v.NearestPlace,
+		Country:      v.Country,
+	}
+
+	if v.Square != nil && v.Square.Southwest != nil && v.Square.Northeast != nil {
+		p.Corners = &BBox{v.Square.Southwest, v.Square.Northeast}
+	}
+
+	return p
+}
+
+// v3Languages holds the v3 response for an available-languages call
+type v3Languages struct {
+	Languages []v3Language `json:"languages"`
+}
+
+// v3Language holds a single language, which v3 names both in English and in the language itself
+type v3Language struct {
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	NativeName string `json:"nativeName"`
+}
+
+// languages converts the v3 response to the Languages returned by the legacy API, whose display name
+// is the native one
+func (v *v3Languages) languages() *Languages {
+	langs := &Languages{make([]Language, 0, len(v.Languages))}
+
+	for _, l := range v.Languages {
+		name := l.NativeName
+		if name == "" {
+			name = l.Name
+		}
+
+		langs.Languages = append(langs.Languages, Language{Code: l.Code, Name: name})
+	}
+
+	return langs
+}
+
+// v3Params renames the legacy query params set by Options.add to their v3 equivalents. A language
+// already set, such as the AutoSuggest input language, is kept.
+func v3Params(vals url.Values) {
+	if lang := vals.Get("lang"); lang != "" && vals.Get("language") == "" {
+		vals.Set("language", lang)
+	}
+
+	vals.Del("lang")
+	vals.Del("corners")
+}
+
+// ----------------------------------------------------------------------------
+// v3 calls
+// ----------------------------------------------------------------------------
+
+// wordsV3 converts a 3 word string to a LatLng position with the v3 convert-to-coordinates call
+func (w *W3W) wordsV3(ctx context.Context, words What3Words, opts *Options) (*Position, error) {
+	vals := url.Values{}
+
+	vals.Set("key", w.apikey)
+	vals.Set("words", words.Normalize().String())
+
+	v := &v3Position{}
+	if err := w.exec(ctx, "/v3/convert-to-coordinates", vals, opts, v); err != nil {
+		return nil, err
+	}
+
+	return w.backfillLanguage(v.position(), opts), nil
+}
+
+// positionV3 converts a LatLng position to a 3 word string with the v3 convert-to-3wa call
+func (w *W3W) positionV3(ctx context.Context, ll LatLng, opts *Options) (*Position, error) {
+	vals := url.Values{}
+
+	vals.Set("key", w.apikey)
+	vals.Set("coordinates", formatLatLng(ll, w.precision))
+
+	v := &v3Position{}
+	if err := w.exec(ctx, "/v3/convert-to-3wa", vals, opts, v); err != nil {
+		return nil, err
+	}
+
+	return w.backfillLanguage(v.position(), opts), nil
+}
+
+// languagesV3 lists the 3 word languages with the v3 available-languages call. It has no per-address
+// or focused list, so every language is returned whatever the words, position or focus.
+func (w *W3W) languagesV3(ctx context.Context, opts *Options) (*Languages, error) {
+	vals := url.Values{}
+	vals.Set("key", w.apikey)
+
+	v := &v3Languages{}
+	if err := w.exec(ctx, "/v3/available-languages", vals, opts, v); err != nil {
+		return nil, err
+	}
+
+	return v.languages(), nil
+}
+
+// WordsGeoJSON converts a 3 word string to the GeoJSON FeatureCollection returned by the v3 API with
+// format=geojson, as raw bytes for passing on to a mapping library untouched. It needs the client to
+// use APIv3, as the legacy API has no GeoJSON output; Position.GeoJSON builds a Feature from either.
+//
+// if any of the words is empty or contains anything other than letters, the returned error is
+// `ErrInvalidWords`
+func (w *W3W) WordsGeoJSON(words What3Words, opts *Options) ([]byte, error) {
+	return w.WordsGeoJSONContext(w.base, words, opts)
+}
+
+// WordsGeoJSONContext converts a 3 word string to raw GeoJSON, aborting the call if ctx is cancelled
+func (w *W3W) WordsGeoJSONContext(ctx context.Context, words What3Words, opts *Options) ([]byte, error) {
+	if w.version != APIv3 {
+		return nil, fmt.Errorf("w3w: GeoJSON output needs API version %q, the client uses %q", APIv3, w.version)
+	}
+
+	if w.normalize {
+		words = words.Normalize()
+	}
+
+	if !words.valid() {
+		return nil, ErrInvalidWords
+	}
+
+	vals := url.Values{}
+
+	vals.Set("key", w.apikey)
+	vals.Set("words", words.Normalize().String())
+	vals.Set("format", "geojson")
+
+	var raw json.RawMessage
+	if err := w.exec(ctx, "/v3/convert-to-coordinates", vals, opts, &raw); err != nil {
+		return nil, err
+	}
+
+	return raw, nil
+}
@@ -0,0 +1,209 @@
+package w3w_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/devork/w3w"
+	"github.com/devork/w3w/w3wtest"
+)
+
+func TestV3Decode(t *testing.T) {
+	s := w3wtest.NewServer(w3w.WithAPIVersion(w3w.APIv3))
+	defer s.Close()
+
+	tests := []struct {
+		name string
+		call func() (*w3w.Position, error)
+	}{
+		{"convert-to-coordinates", func() (*w3w.Position, error) {
+			return s.W3W.Words(w3w.What3Words{"prom", "cape", "pump"}, nil)
+		}},
+		{"convert-to-3wa", func() (*w3w.Position, error) {
+			return s.W3W.Position(w3w.LatLng{51.484463, -0.195405}, nil)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos, err := tt.call()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if want := (w3w.What3Words{"prom", "cape", "pump"}); pos.Words != want {
+				t.Errorf("Words = %v, want %v", pos.Words, want)
+			}
+
+			if want := (w3w.LatLng{51.484463, -0.195405}); pos.Position == nil || *pos.Position != want {
+				t.Errorf("Position = %v, want %v", pos.Position, want)
+			}
+
+			if pos.Corners == nil {
+				t.Fatal("Corners = nil, want the square")
+			}
+
+			if want := (w3w.LatLng{51.484449, -0.195426}); *pos.Corners.SW() != want {
+				t.Errorf("SW = %v, want %v", *pos.Corners.SW(), want)
+			}
+
+			if want := (w3w.LatLng{51.484476, -0.195383}); *pos.Corners.NE() != want {
+				t.Errorf("NE = %v, want %v", *pos.Corners.NE(), want)
+			}
+
+			if pos.Language != "en" || pos.Map != "https://w3w.co/prom.cape.pump" {
+				t.Errorf("Language, Map = %q, %q, want en and the map link", pos.Language, pos.Map)
+			}
+		})
+	}
+}
+
+func TestNearestPlace(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []w3w.Option
+		want    string
+		country string
+	}{
+		{"v3", []w3w.Option{w3w.WithAPIVersion(w3w.APIv3)}, "Fulham, London", "GB"},
+		{"legacy", nil, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := w3wtest.NewServer(tt.opts...)
+			defer s.Close()
+
+			pos, err := s.W3W.Words(w3w.What3Words{"prom", "cape", "pump"}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if pos.NearestPlace != tt.want || pos.Country != tt.country {
+				t.Errorf("NearestPlace, Country = %q, %q, want %q, %q", pos.NearestPlace, pos.Country, tt.want, tt.country)
+			}
+		})
+	}
+}
+
+func TestV3Languages(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(w *w3w.W3W) (*w3w.Languages, error)
+	}{
+		{"Languages", func(w *w3w.W3W) (*w3w.Languages, error) {
+			return w.Languages(nil)
+		}},
+		{"LanguagesFocus", func(w *w3w.W3W) (*w3w.Languages, error) {
+			return w.LanguagesFocus(w3w.LatLng{51.484463, -0.195405}, nil)
+		}},
+		{"LangsW3W", func(w *w3w.W3W) (*w3w.Languages, error) {
+			return w.LangsW3W(w3w.What3Words{"prom", "cape", "pump"}, nil)
+		}},
+		{"LangsPos", func(w *w3w.W3W) (*w3w.Languages, error) {
+			return w.LangsPos(w3w.LatLng{51.484463, -0.195405}, nil)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := w3wtest.NewServer(w3w.WithAPIVersion(w3w.APIv3))
+			defer s.Close()
+
+			// A v3 deployment doesn't serve the legacy path
+			s.SetFixture("/get-languages", w3wtest.Fixture{Status: http.StatusNotFound})
+
+			langs, err := tt.call(s.W3W)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := strings.Join(langs.Codes(), ","); got != "de,en,fr" {
+				t.Errorf("Codes() = %s, want de,en,fr", got)
+			}
+
+			if lang, ok := langs.ByCode("fr"); !ok || lang.Name != "Français" {
+				t.Errorf("ByCode(fr) = %v, %t, want the native name Français", lang, ok)
+			}
+		})
+	}
+}
+
+func TestV3LanguageValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		lang    string
+		wantErr error
+	}{
+		{"supported", "de", nil},
+		{"unsupported", "xx", w3w.ErrUnsupportedLanguage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := w3wtest.NewServer(w3w.WithAPIVersion(w3w.APIv3), w3w.WithLanguageValidation())
+			defer s.Close()
+
+			s.SetFixture("/get-languages", w3wtest.Fixture{Status: http.StatusNotFound})
+
+			opts := &w3w.Options{Lang: tt.lang}
+
+			if _, err := s.W3W.Words(w3w.What3Words{"prom", "cape", "pump"}, opts); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Words() = %v, want %v", err, tt.wantErr)
+			}
+
+			if _, err := s.W3W.Position(w3w.LatLng{51.484463, -0.195405}, opts); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Position() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestV3BestLanguage(t *testing.T) {
+	s := w3wtest.NewServer(w3w.WithAPIVersion(w3w.APIv3))
+	defer s.Close()
+
+	s.SetFixture("/get-languages", w3wtest.Fixture{Status: http.StatusNotFound})
+
+	lang, err := s.W3W.BestLanguage(w3w.LatLng{51.484463, -0.195405}, []string{"es", "FR"}, nil)
+	if err != nil || lang != "fr" {
+		t.Errorf("BestLanguage() = %q, %v, want fr", lang, err)
+	}
+}
+
+func TestV3Ping(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr error
+	}{
+		{"reachable", w3wtest.APIKey, nil},
+		{"bad key", "wrong", w3w.ErrUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := w3wtest.NewServer(w3w.WithAPIVersion(w3w.APIv3))
+			defer s.Close()
+
+			s.SetFixture("/get-languages", w3wtest.Fixture{Status: http.StatusNotFound})
+			queries := recordQueries(s)
+
+			w, err := w3w.New(tt.key, w3w.WithEndpoint(s.URL), w3w.WithAPIVersion(w3w.APIv3))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := w.Ping(context.Background()); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Ping() = %v, want %v", err, tt.wantErr)
+			}
+
+			if n := len(queries()); n != 1 {
+				t.Errorf("made %d requests, want 1", n)
+			}
+		})
+	}
+}
@@ -1,25 +1,96 @@
 package w3w
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"mime"
 	"net/http"
 	"net/url"
+	"os"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 )
 
 const (
+	// Version is the version of this client, sent as part of the User-Agent header
+	Version string = "0.2.0"
+
 	endpoint string = "https://api.what3words.com"
+
+	// userAgent is the default User-Agent header sent on every request
+	userAgent string = "devork-w3w-go/" + Version
+
+	// defaultLang is the language requested when neither the call, the client defaults nor
+	// WithLanguage give one
+	defaultLang string = "en"
+
+	// defaultPrecision is the number of decimal places coordinates are sent with, about 0.1m
+	defaultPrecision int = 6
+
+	// minPrecision and maxPrecision bound WithCoordinatePrecision. Fewer than 5 places can't
+	// reliably pick out a 3m square, and more than 15 is beyond the precision of a float64.
+	minPrecision int = 5
+	maxPrecision int = 15
+
+	// maxPolygonPoints is the most points, including the closing point, the API accepts for
+	// clip-to-polygon
+	maxPolygonPoints int = 25
+
+	// defaultMaxIdleConnsPerHost and defaultIdleConnTimeout tune the default transport's
+	// connection pool so concurrent batch calls reuse connections rather than redialling
+	defaultMaxIdleConnsPerHost int           = 32
+	defaultIdleConnTimeout     time.Duration = 90 * time.Second
+
+	// envAPIKey is the environment variable NewFromEnv reads the API key from
+	envAPIKey string = "W3W_API_KEY"
+
+	// mapURL is the base of the links to squares on the official W3W map
+	mapURL string = "https://w3w.co/"
+
+	// separators are the characters that delimit the words of an address in common formats
+	separators string = ".,/"
+
+	// slashes is the prefix W3W puts in front of a displayed 3 word address
+	slashes string = "///"
+
+	// maxErrorBody caps how much of a failed response body is kept on a StatusError
+	maxErrorBody int64 = 4096
+
+	// defaultMaxBody caps how much of a successful response body is read before giving up
+	defaultMaxBody int64 = 4 << 20
+
+	// maxSnippet caps how much of an unexpected response body is quoted in the error
+	maxSnippet int64 = 200
+
+	// maxDrain caps how much of an unread response body is discarded to allow connection reuse
+	maxDrain int64 = 64 << 10
 )
 
 // Default error codes
 var (
-	ErrNoAPIKey = errors.New("No API Key specified")
-)
+	ErrNoAPIKey      = errors.New("No API Key specified")
+	ErrInvalidWords  = errors.New("Invalid What3Words")
+	ErrInvalidLatLng = errors.New("Invalid LatLng, latitude must be within [-90, 90] and longitude within [-180, 180]")
 
-var (
-	defs = Options{"en", false}
+	ErrUnsupportedLanguage = errors.New("Unsupported language")
+	ErrUnauthorized        = errors.New("Unauthorized, the API key is invalid or revoked")
+	ErrRateLimited         = errors.New("Rate limited, retry after the delay given on the StatusError")
+	ErrInvalidOptions      = errors.New("Invalid options")
+	ErrNoCorners           = errors.New("No corners returned for the square")
+	ErrNoCoordinates       = errors.New("No coordinates returned for the words")
+	ErrResponseTooLarge    = errors.New("Response body too large")
+	ErrNotJSON             = errors.New("Unexpected content type")
 )
 
 // ----------------------------------------------------------------------------
@@ -29,6 +100,174 @@ var (
 // What3Words holds the 3 word position returned or passed to the server
 type What3Words [3]string
 
+// String returns the dotted form of the 3 words, e.g. "index.home.raft". Empty words are kept as
+// empty components so "foo..bar" remains distinguishable.
+func (w What3Words) String() string {
+	return w[0] + "." + w[1] + "." + w[2]
+}
+
+// Equal reports whether w and other hold the same words, ignoring case and surrounding whitespace.
+// Unicode normalization is not applied, so callers comparing input that may use different forms of
+// the same characters should normalize it first.
+func (w What3Words) Equal(other What3Words) bool {
+	return w.Normalize() == other.Normalize()
+}
+
+// Normalize returns the words lowercased and trimmed of surrounding whitespace, the canonical form
+// the W3W service uses. Words already in that form are returned without allocating.
+func (w What3Words) Normalize() What3Words {
+	for i, word := range w {
+		w[i] = strings.ToLower(strings.TrimSpace(word))
+	}
+
+	return w
+}
+
+// valid reports whether each of the 3 words is non-empty and made only of letters. Combining marks are
+// allowed so words in scripts such as Devanagari pass.
+func (w What3Words) valid() bool {
+	for _, word := range w {
+		if word == "" {
+			return false
+		}
+
+		for _, r := range word {
+			if !unicode.IsLetter(r) && !unicode.IsMark(r) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// MarshalJSON encodes the 3 words as their dotted string, e.g. "index.home.raft", or "" if all the
+// words are empty
+func (w What3Words) MarshalJSON() ([]byte, error) {
+	if w == (What3Words{}) {
+		return []byte(`""`), nil
+	}
+
+	return json.Marshal(w.String())
+}
+
+// UnmarshalJSON decodes either the `["index", "home", "raft"]` array form used by the legacy API or
+// the "index.home.raft" dotted string form. An empty string decodes to empty words. The dotted form
+// must have exactly 3 components, but they may be empty so any value from MarshalJSON round-trips.
+func (w *What3Words) UnmarshalJSON(data []byte) error {
+	var arr [3]string
+	if err := json.Unmarshal(data, &arr); err == nil {
+		*w = arr
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("w3w: cannot decode What3Words from %s", data)
+	}
+
+	if s == "" {
+		*w = What3Words{}
+		return nil
+	}
+
+	parts := strings.Split(strings.TrimPrefix(s, slashes), ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("%w: %q has %d words, want 3", ErrInvalidWords, s, len(parts))
+	}
+
+	*w = What3Words{parts[0], parts[1], parts[2]}
+	return nil
+}
+
+// Slashes returns the 3 words in the "///index.home.raft" form W3W uses when presenting addresses
+func (w What3Words) Slashes() string {
+	return slashes + w.String()
+}
+
+// NewWords builds What3Words from its 3 words, checking that each is non-empty and holds no
+// separator such as a dot, comma, slash or whitespace. The returned error wraps ErrInvalidWords.
+func NewWords(a, b, c string) (What3Words, error) {
+	words := What3Words{a, b, c}
+
+	for i, word := range words {
+		if word == "" {
+			return What3Words{}, fmt.Errorf("%w: word %d is empty", ErrInvalidWords, i+1)
+		}
+
+		if strings.ContainsAny(word, separators) || strings.IndexFunc(word, unicode.IsSpace) >= 0 {
+			return What3Words{}, fmt.Errorf("%w: word %d, %q, contains a separator", ErrInvalidWords, i+1, word)
+		}
+	}
+
+	return words, nil
+}
+
+// possibleAddress is the regular expression W3W publishes for strings that could be a 3 word
+// address: three runs of anything but digits, punctuation and whitespace, separated by a full stop
+// in any of the scripts W3W supports
+var possibleAddress = func() *regexp.Regexp {
+	word := `[^0-9` + "`" + `~!@#$%^&*()+\-_=\[{\]}\\|'<,.>?/";:£§º©®\s]+`
+	sep := `[.｡。･・︒។։။۔።।]`
+
+	return regexp.MustCompile(`^/*` + word + sep + word + sep + word + `$`)
+}()
+
+// IsPossibleAddress reports whether s has the form of a 3 word address, e.g. "index.home.raft" or
+// "///インデックス。ホーム。ラフト", without calling the W3W service. A true result doesn't mean the
+// address exists, only that it's worth looking up.
+func IsPossibleAddress(s string) bool {
+	return possibleAddress.MatchString(s)
+}
+
+// ParseWords converts a dotted 3 word string such as "index.home.raft" or "///index.home.raft" into
+// What3Words. Surrounding whitespace is ignored so copy-pasted addresses parse. An error wrapping
+// ErrInvalidWords is returned unless there are exactly 3 non-empty words.
+func ParseWords(s string) (What3Words, error) {
+	return ParseWordsWithDelimiter(s, ".")
+}
+
+// ParseWordsWithDelimiter converts a 3 word string separated by any one of the characters in delims
+// into What3Words, e.g. with delims of ". ,/" all of "index.home.raft", "index home raft" and
+// "index,home,raft" parse. The words must all be separated by the same delimiter; mixing them, as in
+// "index.home raft", is rejected as ambiguous. As with ParseWords, a leading "///" and surrounding
+// whitespace are ignored.
+func ParseWordsWithDelimiter(s string, delims string) (What3Words, error) {
+	var words What3Words
+
+	trimmed := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(s), slashes))
+
+	sep := ""
+	for _, r := range trimmed {
+		if !strings.ContainsRune(delims, r) || sep == string(r) {
+			continue
+		}
+
+		if sep != "" {
+			return words, fmt.Errorf("%w: %q mixes the delimiters %q and %q", ErrInvalidWords, s, sep, string(r))
+		}
+		sep = string(r)
+	}
+
+	parts := []string{trimmed}
+	if sep != "" {
+		parts = strings.Split(trimmed, sep)
+	}
+
+	if len(parts) != len(words) {
+		return words, fmt.Errorf("%w: %q has %d words, expected 3", ErrInvalidWords, s, len(parts))
+	}
+
+	for i, p := range parts {
+		if p == "" {
+			return words, fmt.Errorf("%w: %q has an empty word at position %d", ErrInvalidWords, s, i+1)
+		}
+		words[i] = p
+	}
+
+	return words, nil
+}
+
 // ----------------------------------------------------------------------------
 // LatLng type
 // ----------------------------------------------------------------------------
@@ -46,6 +285,42 @@ func (ll *LatLng) Lng() float64 {
 	return ll[1]
 }
 
+// MarshalJSON encodes the position as a `[lat, lng]` array
+func (ll LatLng) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]float64(ll))
+}
+
+// UnmarshalJSON decodes either the `[lat, lng]` array form or the `{"lat": ..., "lng": ...}` object
+// form of a position
+func (ll *LatLng) UnmarshalJSON(data []byte) error {
+	var arr [2]float64
+	if err := json.Unmarshal(data, &arr); err == nil {
+		*ll = arr
+		return nil
+	}
+
+	var obj struct {
+		Lat *float64 `json:"lat"`
+		Lng *float64 `json:"lng"`
+	}
+
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return fmt.Errorf("w3w: cannot decode LatLng from %s", data)
+	}
+
+	if obj.Lat == nil || obj.Lng == nil {
+		return fmt.Errorf("w3w: LatLng object %s requires both lat and lng", data)
+	}
+
+	*ll = LatLng{*obj.Lat, *obj.Lng}
+	return nil
+}
+
+// valid reports whether the latitude is within [-90, 90] and the longitude within [-180, 180]
+func (ll *LatLng) valid() bool {
+	return ll.Lat() >= -90 && ll.Lat() <= 90 && ll.Lng() >= -180 && ll.Lng() <= 180
+}
+
 // ----------------------------------------------------------------------------
 // BBox type
 // ----------------------------------------------------------------------------
@@ -63,6 +338,16 @@ func (b *BBox) NE() *LatLng {
 	return b[1]
 }
 
+// ----------------------------------------------------------------------------
+// Circle struct
+// ----------------------------------------------------------------------------
+
+// Circle is an area of RadiusKm kilometres around Center
+type Circle struct {
+	Center   *LatLng
+	RadiusKm float64
+}
+
 // ----------------------------------------------------------------------------
 // Position struct
 // ----------------------------------------------------------------------------
@@ -74,6 +359,61 @@ type Position struct {
 	Position *LatLng    `json:"position"`
 	Corners  *BBox      `json:"corners"`
 	Language string     `json:"language"`
+	Map      string     `json:"map"`
+
+	// NearestPlace describes the closest place to the square, e.g. "Bayswater, London". It is
+	// only returned by the v3 API and is empty otherwise.
+	NearestPlace string `json:"nearestPlace"`
+
+	// Country is the ISO 3166-1 alpha-2 code of the country the square is in. It is only returned
+	// by the v3 API and is empty otherwise.
+	Country string `json:"country"`
+}
+
+// MapURL returns a link to the square on the official W3W map. The map URL from the response is used
+// if the server sent one, otherwise it is built from the words. An empty string is returned if there
+// are no words.
+func (p *Position) MapURL() string {
+	if p.Map != "" {
+		return p.Map
+	}
+
+	if p.Words == (What3Words{}) {
+		return ""
+	}
+
+	return mapURL + p.Words.String()
+}
+
+// IsZero reports whether the position holds no words and no coordinates. Failed calls return an
+// error rather than a zero Position, so a zero result means the server genuinely returned nothing.
+func (p *Position) IsZero() bool {
+	return p == nil || (p.Words == What3Words{} && p.Position == nil)
+}
+
+// UnmarshalJSON decodes the position as sent by the server. Corners that are absent, null, an empty
+// object or array, malformed or missing either corner all leave Corners nil rather than failing the
+// decode.
+func (p *Position) UnmarshalJSON(data []byte) error {
+	type position Position
+
+	aux := struct {
+		*position
+		Corners json.RawMessage `json:"corners"`
+	}{position: (*position)(p)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	p.Corners = nil
+
+	var corners BBox
+	if json.Unmarshal(aux.Corners, &corners) == nil && corners.SW() != nil && corners.NE() != nil {
+		p.Corners = &corners
+	}
+
+	return nil
 }
 
 // ----------------------------------------------------------------------------
@@ -95,6 +435,27 @@ type Languages struct {
 	Languages []Language `json:"languages"`
 }
 
+// ByCode returns the language with the given code, compared case-insensitively
+func (l *Languages) ByCode(code string) (*Language, bool) {
+	for i := range l.Languages {
+		if strings.EqualFold(l.Languages[i].Code, code) {
+			return &l.Languages[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// Codes returns the codes of all the languages, in the order the server listed them
+func (l *Languages) Codes() []string {
+	codes := make([]string, 0, len(l.Languages))
+	for _, lang := range l.Languages {
+		codes = append(codes, lang.Code)
+	}
+
+	return codes
+}
+
 // ----------------------------------------------------------------------------
 // Options struct
 // ----------------------------------------------------------------------------
@@ -103,19 +464,301 @@ type Languages struct {
 type Options struct {
 	Lang    string
 	Corners bool
+
+	// NoCorners turns off Corners set in the client defaults for a call. Setting both Corners and
+	// NoCorners is an error.
+	NoCorners bool
+
+	// InputLang is the language of the partial words given to AutoSuggest, when it differs from
+	// the language results should be displayed in. If set, AutoSuggest sends it as `language` and
+	// Locale, or Lang if that's unset, as `locale`; otherwise Lang is used for both. Other calls
+	// ignore it.
+	InputLang string
+
+	// Locale picks the display form of languages written in more than one script, such as "zh_tr"
+	// or "zh_si" for traditional or simplified Chinese. It is sent as `locale` alongside `lang`.
+	Locale string
+
+	// Focus biases AutoSuggest results towards the given position
+	Focus *LatLng
+
+	// ClipToCountry restricts AutoSuggest results to the given ISO 3166-1 alpha-2 country codes.
+	// Codes are trimmed and uppercased, and an entry may itself be a comma separated list.
+	ClipToCountry []string
+
+	// NResults sets how many AutoSuggest results are returned, and NFocusResults how many of those
+	// are ranked by distance from Focus. Zero leaves the API default.
+	NResults      int
+	NFocusResults int
+
+	// ClipToBoundingBox restricts AutoSuggest results to the given box, whose SW corner must be
+	// south and west of its NE corner
+	ClipToBoundingBox *BBox
+
+	// ClipToCircle restricts AutoSuggest results to within the given circle
+	ClipToCircle *Circle
+
+	// ClipToPolygon restricts AutoSuggest results to within the given polygon of up to 25 points.
+	// The ring is closed automatically if the first and last points differ.
+	ClipToPolygon []*LatLng
+
+	// NoCache bypasses the client's response cache, if WithCache enabled one, for the call.
+	// UseCache turns off NoCache set in the client defaults for a call. Setting both is an error.
+	NoCache  bool
+	UseCache bool
+}
+
+// clone returns a copy of o that shares no mutable state with it
+func (o *Options) clone() *Options {
+	c := *o
+
+	if o.Focus != nil {
+		focus := *o.Focus
+		c.Focus = &focus
+	}
+
+	if o.ClipToCountry != nil {
+		c.ClipToCountry = append([]string{}, o.ClipToCountry...)
+	}
+
+	if o.ClipToCircle != nil {
+		circle := *o.ClipToCircle
+		if circle.Center != nil {
+			center := *circle.Center
+			circle.Center = &center
+		}
+		c.ClipToCircle = &circle
+	}
+
+	if o.ClipToPolygon != nil {
+		c.ClipToPolygon = make([]*LatLng, len(o.ClipToPolygon))
+		for i, ll := range o.ClipToPolygon {
+			if ll != nil {
+				point := *ll
+				c.ClipToPolygon[i] = &point
+			}
+		}
+	}
+
+	if b := o.ClipToBoundingBox; b != nil {
+		c.ClipToBoundingBox = &BBox{}
+		for i, ll := range b {
+			if ll != nil {
+				corner := *ll
+				c.ClipToBoundingBox[i] = &corner
+			}
+		}
+	}
+
+	return &c
+}
+
+// merge returns a copy of o with every field that is set in over replacing the one in o, so a call
+// can override single fields of the client defaults. The on and off flags Corners and NoCorners, and
+// NoCache and UseCache, are taken as a pair, so either can override the defaults.
+func (o *Options) merge(over *Options) *Options {
+	m := o.clone()
+	c := over.clone()
+
+	if c.Lang != "" {
+		m.Lang = c.Lang
+	}
+
+	if c.Corners || c.NoCorners {
+		m.Corners, m.NoCorners = c.Corners, c.NoCorners
+	}
+
+	if c.InputLang != "" {
+		m.InputLang = c.InputLang
+	}
+
+	if c.Locale != "" {
+		m.Locale = c.Locale
+	}
+
+	if c.Focus != nil {
+		m.Focus = c.Focus
+	}
+
+	if c.ClipToCountry != nil {
+		m.ClipToCountry = c.ClipToCountry
+	}
+
+	if c.NResults > 0 {
+		m.NResults = c.NResults
+	}
+
+	if c.NFocusResults > 0 {
+		m.NFocusResults = c.NFocusResults
+	}
+
+	if c.ClipToBoundingBox != nil {
+		m.ClipToBoundingBox = c.ClipToBoundingBox
+	}
+
+	if c.ClipToCircle != nil {
+		m.ClipToCircle = c.ClipToCircle
+	}
+
+	if c.ClipToPolygon != nil {
+		m.ClipToPolygon = c.ClipToPolygon
+	}
+
+	if c.NoCache || c.UseCache {
+		m.NoCache, m.UseCache = c.NoCache, c.UseCache
+	}
+
+	return m
+}
+
+// validate checks the options that can't be sent as given, returning an error wrapping
+// ErrInvalidOptions for the first problem found
+func (o *Options) validate() error {
+	if o.Corners && o.NoCorners {
+		return fmt.Errorf("%w: Corners and NoCorners are both set", ErrInvalidOptions)
+	}
+
+	if o.NoCache && o.UseCache {
+		return fmt.Errorf("%w: NoCache and UseCache are both set", ErrInvalidOptions)
+	}
+
+	if b := o.ClipToBoundingBox; b != nil {
+		if b.SW() == nil || b.NE() == nil || !b.SW().valid() || !b.NE().valid() {
+			return fmt.Errorf("%w: clip-to-bounding-box requires two valid corners", ErrInvalidOptions)
+		}
+
+		if b.SW().Lat() > b.NE().Lat() || b.SW().Lng() > b.NE().Lng() {
+			return fmt.Errorf("%w: clip-to-bounding-box SW corner must be south west of its NE corner", ErrInvalidOptions)
+		}
+	}
+
+	if c := o.ClipToCircle; c != nil {
+		if c.Center == nil || !c.Center.valid() {
+			return fmt.Errorf("%w: clip-to-circle requires a valid center", ErrInvalidOptions)
+		}
+
+		if !(c.RadiusKm > 0) {
+			return fmt.Errorf("%w: clip-to-circle radius must be positive", ErrInvalidOptions)
+		}
+	}
+
+	if o.ClipToPolygon != nil {
+		for _, ll := range o.ClipToPolygon {
+			if ll == nil || !ll.valid() {
+				return fmt.Errorf("%w: clip-to-polygon requires valid points", ErrInvalidOptions)
+			}
+		}
+
+		ring := closedRing(o.ClipToPolygon)
+
+		if len(ring) < 4 {
+			return fmt.Errorf("%w: clip-to-polygon requires at least 3 points", ErrInvalidOptions)
+		}
+
+		if len(ring) > maxPolygonPoints {
+			return fmt.Errorf("%w: clip-to-polygon has %d points, the limit is %d", ErrInvalidOptions, len(ring), maxPolygonPoints)
+		}
+	}
+
+	return nil
 }
 
-func (o *Options) add(v *url.Values) {
+// closedRing returns points with the first point appended if the ring isn't already closed
+func closedRing(points []*LatLng) []*LatLng {
+	if len(points) == 0 {
+		return points
+	}
+
+	first, last := points[0], points[len(points)-1]
+	if first == nil || last == nil || *first == *last {
+		return points
+	}
+
+	return append(points[:len(points):len(points)], points[0])
+}
+
+// lang returns the language to request, defaulting to "en"
+func (o *Options) lang() string {
 	if o.Lang == "" {
-		v.Set("lang", "en")
-	} else {
-		v.Set("lang", o.Lang)
+		return defaultLang
+	}
+
+	return o.Lang
+}
+
+// defaultOptions returns the options used for calls when none are given, a fresh copy each time so
+// no two clients share them. The language is filled in by New from WithLanguage.
+func defaultOptions() *Options {
+	return &Options{}
+}
+
+// query returns a copy of vals with the query params for o added, formatting any coordinates to prec
+// decimal places. Neither o nor vals is modified, so the same options can be used by concurrent calls.
+func (o *Options) query(vals url.Values, prec int) url.Values {
+	v := make(url.Values, len(vals))
+	for k, vs := range vals {
+		v[k] = append([]string(nil), vs...)
+	}
+
+	v.Set("lang", o.lang())
+
+	if o.Locale != "" {
+		v.Set("locale", o.Locale)
 	}
 
 	if o.Corners {
 		v.Set("corners", "true")
 	}
 
+	if o.Focus != nil {
+		v.Set("focus", formatLatLng(*o.Focus, prec))
+	}
+
+	var countries []string
+	for _, cs := range o.ClipToCountry {
+		for _, c := range strings.Split(cs, ",") {
+			if c = strings.ToUpper(strings.TrimSpace(c)); c != "" {
+				countries = append(countries, c)
+			}
+		}
+	}
+
+	if len(countries) > 0 {
+		v.Set("clip-to-country", strings.Join(countries, ","))
+	}
+
+	if o.NResults > 0 {
+		v.Set("n-results", strconv.Itoa(o.NResults))
+	}
+
+	if o.NFocusResults > 0 {
+		v.Set("n-focus-results", strconv.Itoa(o.NFocusResults))
+	}
+
+	if b := o.ClipToBoundingBox; b != nil && b.SW() != nil && b.NE() != nil {
+		v.Set("clip-to-bounding-box", formatLatLng(*b.SW(), prec)+","+formatLatLng(*b.NE(), prec))
+	}
+
+	if c := o.ClipToCircle; c != nil && c.Center != nil {
+		v.Set("clip-to-circle", formatLatLng(*c.Center, prec)+","+strconv.FormatFloat(c.RadiusKm, 'f', -1, 64))
+	}
+
+	if len(o.ClipToPolygon) > 0 {
+		var points []string
+		for _, ll := range closedRing(o.ClipToPolygon) {
+			if ll != nil {
+				points = append(points, formatLatLng(*ll, prec))
+			}
+		}
+		v.Set("clip-to-polygon", strings.Join(points, ","))
+	}
+
+	return v
+}
+
+// formatLatLng formats ll as the "lat,lng" pair expected in W3W query params, to prec decimal places
+func formatLatLng(ll LatLng, prec int) string {
+	return strconv.FormatFloat(ll[0], 'f', prec, 64) + "," + strconv.FormatFloat(ll[1], 'f', prec, 64)
 }
 
 // ----------------------------------------------------------------------------
@@ -127,92 +770,703 @@ type W3W struct {
 	apikey   string
 	client   *http.Client
 	defaults *Options
+	endpoint string
+	langs    *langCache
+	rate     *rateLimits
+	ua       string
+	retry    retryPolicy
+	timeout  time.Duration
+
+	batchSize int
+	langCheck *langValidator
+	logger    RequestLogger
+	tracer    Tracer
+	version   string
+	precision int
+	headers   http.Header
+	metrics   Metrics
+	maxBody   int64
+	base      context.Context
+	insecure  bool
+	cache     *lruCache
+	normalize bool
+	recordDir string
+	replayDir string
+	sem       chan struct{}
+	lang      string
+
+	itemTimeout time.Duration
 }
 
-// New returns a W3W with the given API key. The options defaults allows for sensible defaults to be
-// associated with each W3W call.
+// New returns a W3W with the given API key, configured by opts. Sensible defaults to be associated
+// with each W3W call can be given with WithDefaults, or by passing an *Options directly, e.g.
+// `New(key, &Options{Lang: "de"})`.
 //
 // if the key is missing or empty, the returned error is `ErrNoAPIKey`
-func New(apikey string, defaults *Options) (*W3W, error) {
+func New(apikey string, opts ...Option) (*W3W, error) {
 	if apikey == "" || strings.TrimSpace(apikey) == "" {
 		return nil, ErrNoAPIKey
 	}
 
-	if defaults == nil {
-		return &W3W{apikey, &http.Client{}, &defs}, nil
+	client := &http.Client{Transport: defaultTransport()}
+
+	w := &W3W{
+		apikey:   apikey,
+		client:   client,
+		defaults: defaultOptions(),
+		endpoint: endpoint,
+		rate:     newRateLimits(),
+		ua:       userAgent,
+		retry:    retryPolicy{attempts: 1},
+
+		batchSize: defaultBatchConcurrency,
+		logger:    nopLogger{},
+		metrics:   nopMetrics{},
+		maxBody:   defaultMaxBody,
+		base:      context.Background(),
+		version:   APIv1,
+		precision: defaultPrecision,
+		lang:      defaultLang,
+	}
+
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+
+		if err := opt.apply(w); err != nil {
+			return nil, err
+		}
 	}
 
-	return &W3W{apikey, &http.Client{}, defaults}, nil
+	if w.insecure && w.client == client {
+		log.Println("w3w: WARNING: TLS certificate verification is disabled by WithInsecureSkipVerify, never use this in production")
+		client.Transport = insecureTransport()
+	}
+
+	w.client = w.wrapClient(w.client)
+
+	if w.defaults.Lang == "" {
+		w.defaults.Lang = w.lang
+	}
 
+	return w, nil
+}
+
+// NewFromEnv returns a W3W using the API key from the W3W_API_KEY environment variable, configured
+// by opts as for New.
+//
+// if the variable is unset or blank, the returned error is `ErrNoAPIKey`
+func NewFromEnv(opts ...Option) (*W3W, error) {
+	return New(os.Getenv(envAPIKey), opts...)
+}
+
+// Clone returns a copy of the client with its own copy of the defaults, so they can be changed
+// without affecting w. The HTTP client, language cache and rate limit details are shared.
+func (w *W3W) Clone() *W3W {
+	c := *w
+	c.defaults = w.defaults.clone()
+	c.headers = w.headers.Clone()
+
+	return &c
+}
+
+// WithDefaultLang returns a clone of the client whose default language is lang. An empty lang falls
+// back to the language given by WithLanguage, or "en" without it.
+func (w *W3W) WithDefaultLang(lang string) *W3W {
+	if lang == "" {
+		lang = w.lang
+	}
+
+	c := w.Clone()
+	c.defaults.Lang = lang
+
+	return c
 }
 
 // Words converts a 3 word string to LatLng position
 func (w *W3W) Words(words What3Words, opts *Options) (*Position, error) {
-	vals := url.Values{}
+	return w.WordsContext(w.base, words, opts)
+}
 
-	vals.Set("key", w.apikey)
-	vals.Set("string", strings.Join(words[:], "."))
+// WordsString converts a dotted 3 word string, such as "index.home.raft" from a form field, to LatLng
+// position. The string is parsed with ParseWords and any parse error is returned as is.
+func (w *W3W) WordsString(s string, opts *Options) (*Position, error) {
+	words, err := ParseWords(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.Words(words, opts)
+}
+
+// WordsContext converts a 3 word string to LatLng position, aborting the call if ctx is cancelled.
+//
+// if any of the words is empty or contains anything other than letters, the returned error is
+// `ErrInvalidWords`. With WithAutoNormalize, the words are normalized before they're checked.
+func (w *W3W) WordsContext(ctx context.Context, words What3Words, opts *Options) (*Position, error) {
+	if w.normalize {
+		words = words.Normalize()
+	}
 
-	pos, err := w.exec(endpoint+"/w3w", &vals, opts, &Position{})
+	if !words.valid() {
+		return nil, ErrInvalidWords
+	}
+
+	return w.cached("words", words.Normalize().String(), opts, func() (*Position, error) {
+		if w.version == APIv3 {
+			return w.wordsV3(ctx, words, opts)
+		}
+
+		vals := url.Values{}
+
+		vals.Set("key", w.apikey)
+		vals.Set("string", words.Normalize().String())
 
-	return pos.(*Position), err
+		pos := &Position{}
+		if err := w.exec(ctx, "/w3w", vals, opts, pos); err != nil {
+			return nil, err
+		}
+
+		return w.backfillLanguage(pos, opts), nil
+	})
 }
 
-// Position converts a 3 word string to LatLng position
+// backfillLanguage sets the language of pos to the one requested by opts if the server left it out,
+// as the legacy endpoints don't always include it, so callers can rely on Position.Language
+func (w *W3W) backfillLanguage(pos *Position, opts *Options) *Position {
+	if pos.Language == "" {
+		pos.Language = w.options(opts).Lang
+	}
+
+	return pos
+}
+
+// Position converts a LatLng position to a 3 word string
 func (w *W3W) Position(ll LatLng, opts *Options) (*Position, error) {
-	vals := url.Values{}
+	return w.PositionContext(w.base, ll, opts)
+}
 
-	vals.Set("key", w.apikey)
-	vals.Set("position", fmt.Sprintf("%.15f,%.15f", ll[0], ll[1]))
+// PositionLatLng converts the position given as separate latitude and longitude to a 3 word string
+func (w *W3W) PositionLatLng(lat, lng float64, opts *Options) (*Position, error) {
+	return w.Position(LatLng{lat, lng}, opts)
+}
+
+// PositionContext converts a LatLng position to a 3 word string, aborting the call if ctx is cancelled.
+//
+// if the position is out of range, the returned error is `ErrInvalidLatLng`
+func (w *W3W) PositionContext(ctx context.Context, ll LatLng, opts *Options) (*Position, error) {
+	if !ll.valid() {
+		return nil, ErrInvalidLatLng
+	}
+
+	return w.cached("position", formatLatLng(ll, w.precision), opts, func() (*Position, error) {
+		if w.version == APIv3 {
+			return w.positionV3(ctx, ll, opts)
+		}
+
+		vals := url.Values{}
+
+		vals.Set("key", w.apikey)
+		vals.Set("position", formatLatLng(ll, w.precision))
+
+		pos := &Position{}
+		if err := w.exec(ctx, "/position", vals, opts, pos); err != nil {
+			return nil, err
+		}
+
+		return w.backfillLanguage(pos, opts), nil
+	})
+}
+
+// RoundTripVerify converts words to a position and that position back to words, reporting whether
+// the result matches words case-insensitively. It is a data quality check for imported addresses.
+// An error from either call is returned as is.
+//
+// if the first call returns no coordinates, the returned error is `ErrNoCoordinates`
+func (w *W3W) RoundTripVerify(words What3Words, opts *Options) (bool, error) {
+	return w.RoundTripVerifyContext(w.base, words, opts)
+}
+
+// RoundTripVerifyContext converts words to a position and back, reporting whether the result matches
+// words, aborting the calls if ctx is cancelled
+func (w *W3W) RoundTripVerifyContext(ctx context.Context, words What3Words, opts *Options) (bool, error) {
+	pos, err := w.WordsContext(ctx, words, opts)
+	if err != nil {
+		return false, err
+	}
+
+	if pos.Position == nil {
+		return false, ErrNoCoordinates
+	}
+
+	back, err := w.PositionContext(ctx, *pos.Position, opts)
+	if err != nil {
+		return false, err
+	}
+
+	return words.Equal(back.Words), nil
+}
 
-	pos, err := w.exec(endpoint+"/position", &vals, opts, &Position{})
-	return pos.(*Position), err
+// Square returns the bounding box of the 3 metre square ll falls in, without the rest of the position
+// details. Corners are always requested, whatever opts says.
+//
+// if the server doesn't return the corners, the returned error is `ErrNoCorners`
+func (w *W3W) Square(ll LatLng, opts *Options) (*BBox, error) {
+	return w.SquareContext(w.base, ll, opts)
+}
+
+// SquareContext returns the bounding box of the 3 metre square ll falls in, aborting the call if ctx
+// is cancelled
+func (w *W3W) SquareContext(ctx context.Context, ll LatLng, opts *Options) (*BBox, error) {
+	o := w.options(opts).clone()
+	o.Corners, o.NoCorners = true, false
+
+	pos, err := w.PositionContext(ctx, ll, o)
+	if err != nil {
+		return nil, err
+	}
+
+	if pos.Corners == nil || pos.Corners.SW() == nil || pos.Corners.NE() == nil {
+		return nil, ErrNoCorners
+	}
+
+	return pos.Corners, nil
+}
+
+// Coordinates returns just the coordinates of the square words identifies, for callers that don't
+// need the rest of the position details
+//
+// if the server doesn't return a position, the returned error is `ErrNoCoordinates`
+func (w *W3W) Coordinates(words What3Words, opts *Options) (LatLng, error) {
+	return w.CoordinatesContext(w.base, words, opts)
+}
+
+// CoordinatesContext returns just the coordinates of the square words identifies, aborting the call
+// if ctx is cancelled
+func (w *W3W) CoordinatesContext(ctx context.Context, words What3Words, opts *Options) (LatLng, error) {
+	pos, err := w.WordsContext(ctx, words, opts)
+	if err != nil {
+		return LatLng{}, err
+	}
+
+	if pos.Position == nil {
+		return LatLng{}, ErrNoCoordinates
+	}
+
+	return *pos.Position, nil
 }
 
 // LangsW3W obtains the list of available 3 word lanagues for a given W3W position
 func (w *W3W) LangsW3W(words What3Words, opts *Options) (*Languages, error) {
+	return w.LangsW3WContext(w.base, words, opts)
+}
+
+// LangsW3WContext obtains the list of available 3 word lanagues for a given W3W position, aborting
+// the call if ctx is cancelled.
+//
+// if any of the words is empty or contains anything other than letters, the returned error is
+// `ErrInvalidWords`
+func (w *W3W) LangsW3WContext(ctx context.Context, words What3Words, opts *Options) (*Languages, error) {
+	if !words.valid() {
+		return nil, ErrInvalidWords
+	}
+
 	vals := url.Values{}
 	vals.Set("key", w.apikey)
-	vals.Set("string", strings.Join(words[:], "."))
-
-	langs, err := w.exec(endpoint+"/get-languages", &vals, opts, &Languages{[]Language{}})
+	vals.Set("string", words.Normalize().String())
 
-	return langs.(*Languages), err
+	return w.languages(ctx, vals, opts)
 }
 
 // LangsPos obtains the list of available 3 word lanagues for a given LatLng position
 func (w *W3W) LangsPos(ll LatLng, opts *Options) (*Languages, error) {
+	return w.LangsPosContext(w.base, ll, opts)
+}
+
+// LangsPosContext obtains the list of available 3 word lanagues for a given LatLng position,
+// aborting the call if ctx is cancelled.
+//
+// if the position is out of range, the returned error is `ErrInvalidLatLng`
+func (w *W3W) LangsPosContext(ctx context.Context, ll LatLng, opts *Options) (*Languages, error) {
+	if !ll.valid() {
+		return nil, ErrInvalidLatLng
+	}
+
+	vals := url.Values{}
+	vals.Set("key", w.apikey)
+	vals.Set("position", formatLatLng(ll, w.precision))
+
+	return w.languages(ctx, vals, opts)
+}
+
+// BestLanguage returns the first of the preferred language codes available for the LatLng position,
+// for picking a display language the way Accept-Language does. Codes are matched case-insensitively
+// and the code is returned as the server lists it. If none of preferred is available, the language
+// opts would request, falling back to the client default, is returned.
+func (w *W3W) BestLanguage(ll LatLng, preferred []string, opts *Options) (string, error) {
+	return w.BestLanguageContext(w.base, ll, preferred, opts)
+}
+
+// BestLanguageContext returns the first of the preferred language codes available for the LatLng
+// position, aborting the call if ctx is cancelled
+func (w *W3W) BestLanguageContext(ctx context.Context, ll LatLng, preferred []string, opts *Options) (string, error) {
+	langs, err := w.LangsPosContext(ctx, ll, opts)
+	if err != nil {
+		return "", err
+	}
+
+	for _, code := range preferred {
+		if lang, ok := langs.ByCode(code); ok {
+			return lang.Code, nil
+		}
+	}
+
+	return w.options(opts).lang(), nil
+}
+
+// Languages obtains the list of all 3 word languages supported by the W3W service
+func (w *W3W) Languages(opts *Options) (*Languages, error) {
+	return w.LanguagesContext(w.base, opts)
+}
+
+// LanguagesContext obtains the list of all 3 word languages supported by the W3W service, aborting
+// the call if ctx is cancelled
+func (w *W3W) LanguagesContext(ctx context.Context, opts *Options) (*Languages, error) {
+	vals := url.Values{}
+	vals.Set("key", w.apikey)
+
+	return w.languages(ctx, vals, opts)
+}
+
+// LanguagesFocus obtains the list of all 3 word languages, passing focus so the W3W service can
+// prioritise the languages used near it. The languages are returned in the order the server gives.
+func (w *W3W) LanguagesFocus(focus LatLng, opts *Options) (*Languages, error) {
+	return w.LanguagesFocusContext(w.base, focus, opts)
+}
+
+// LanguagesFocusContext obtains the list of all 3 word languages prioritised around focus, aborting
+// the call if ctx is cancelled.
+//
+// if the focus is out of range, the returned error is `ErrInvalidLatLng`
+func (w *W3W) LanguagesFocusContext(ctx context.Context, focus LatLng, opts *Options) (*Languages, error) {
+	if !focus.valid() {
+		return nil, ErrInvalidLatLng
+	}
+
+	o := w.options(opts).clone()
+	o.Focus = &focus
+
+	vals := url.Values{}
+	vals.Set("key", w.apikey)
+
+	return w.languages(ctx, vals, o)
+}
+
+// Ping checks that the W3W service is reachable and accepts the API key, e.g. for a readiness
+// probe, by fetching the languages list, one of the cheapest calls the service offers. The language
+// cache is bypassed so a stale entry can't hide an outage. It returns nil on success, or the error
+// from the call, such as a *StatusError matching ErrUnauthorized for a bad key. With APIv3 the v3
+// available-languages call is used instead.
+func (w *W3W) Ping(ctx context.Context) error {
 	vals := url.Values{}
 	vals.Set("key", w.apikey)
-	vals.Set("position", fmt.Sprintf("%.15f,%.15f", ll[0], ll[1]))
 
-	langs, err := w.exec(endpoint+"/get-languages", &vals, opts, &Languages{[]Language{}})
+	if w.version == APIv3 {
+		return w.exec(ctx, "/v3/available-languages", vals, nil, &v3Languages{})
+	}
 
-	return langs.(*Languages), err
+	return w.exec(ctx, "/get-languages", vals, nil, &Languages{})
 }
 
-func (w *W3W) exec(url string, vals *url.Values, opts *Options, in interface{}) (interface{}, error) {
+// languages performs a get-languages call, serving it from the language cache when enabled
+func (w *W3W) languages(ctx context.Context, vals url.Values, opts *Options) (*Languages, error) {
+	key := langCacheKey(vals, w.options(opts), w.precision)
 
-	if opts != nil {
-		opts.add(vals)
+	if langs, ok := w.langs.get(key); ok {
+		return langs, nil
+	}
+
+	var langs *Languages
+
+	if w.version == APIv3 {
+		v3, err := w.languagesV3(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		langs = v3
 	} else {
-		w.defaults.add(vals)
+		langs = &Languages{[]Language{}}
+		if err := w.exec(ctx, "/get-languages", vals, opts, langs); err != nil {
+			return nil, err
+		}
+	}
+
+	w.langs.put(key, langs)
+
+	return langs, nil
+}
+
+// options returns the client defaults with opts merged over them, or the defaults alone if opts is
+// nil
+func (w *W3W) options(opts *Options) *Options {
+	if opts == nil {
+		return w.defaults
+	}
+
+	return w.defaults.merge(opts)
+}
+
+// exec performs the GET request against path and decodes the JSON response into in, retrying
+// according to the client's retry policy. If ctx is cancelled before the response arrives, the
+// request and any further retries are aborted and ctx.Err() is returned. Any non-2xx response is
+// returned as a *StatusError without attempting to decode the body, and an error payload in a
+// successful response is returned as an *APIError. The client timeout covers both the wait for a
+// concurrency slot and the call itself.
+func (w *W3W) exec(ctx context.Context, path string, vals url.Values, opts *Options, in interface{}) error {
+	parent := ctx
+
+	if w.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.timeout)
+		defer cancel()
+	}
+
+	err := w.send(ctx, path, vals, opts, in)
+
+	if err != nil && parent.Err() == nil && ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("w3w: call exceeded timeout of %s: %w", w.timeout, ctx.Err())
+	}
+
+	return err
+}
+
+// send waits for a slot under the concurrency cap and makes the call
+func (w *W3W) send(ctx context.Context, path string, vals url.Values, opts *Options, in interface{}) error {
+	if err := w.acquire(ctx); err != nil {
+		return err
+	}
+	defer w.release()
+
+	return w.observe(ctx, path, vals, func(ctx context.Context) error {
+		return w.call(ctx, path, vals, opts, in)
+	})
+}
+
+// observe runs fn as a call to path with the query params vals, recording its metrics and span
+func (w *W3W) observe(ctx context.Context, path string, vals url.Values, fn func(ctx context.Context) error) error {
+	ctx, finish := w.startSpan(ctx, path, vals)
+
+	w.metrics.IncRequest(path)
+	start := time.Now()
+
+	err := fn(ctx)
+
+	w.metrics.ObserveLatency(path, time.Since(start))
+	if err != nil {
+		w.metrics.IncError(Category(err).String())
+	}
+
+	finish(err)
+
+	return err
+}
+
+// call applies the options to a call and attempts it
+func (w *W3W) call(ctx context.Context, path string, vals url.Values, opts *Options, in interface{}) error {
+	o := w.options(opts)
+
+	if err := o.validate(); err != nil {
+		return err
+	}
+
+	if err := w.langCheck.check(ctx, w, o.Lang); err != nil {
+		return err
+	}
+
+	q := o.query(vals, w.precision)
+
+	if w.version == APIv3 {
+		v3Params(q)
+	}
+
+	hdr := http.Header{}
+	if lang := o.Lang; lang != "" {
+		hdr.Set("Accept-Language", lang)
+	}
+
+	return w.attempt(ctx, w.buildURL(path, q), hdr, in)
+}
+
+// buildURL returns the full URL for a call to path with the query params vals. It includes the API
+// key, so it must be passed through redactKey before being logged or exposed.
+func (w *W3W) buildURL(path string, vals url.Values) string {
+	return w.endpoint + path + "?" + vals.Encode()
+}
+
+// attempt calls do until it succeeds, fails with an error that isn't worth retrying, or the retry
+// policy runs out of attempts
+func (w *W3W) attempt(ctx context.Context, url string, hdr http.Header, in interface{}) error {
+	for attempt := 1; ; attempt++ {
+		retry, err := w.do(ctx, url, hdr, in)
+
+		if err == nil || !retry || attempt >= w.retry.attempts {
+			return err
+		}
+
+		if err := w.retry.wait(ctx, attempt); err != nil {
+			return err
+		}
+	}
+}
+
+// do makes a single attempt at a call with the extra headers hdr, reporting whether a failure is
+// worth retrying
+func (w *W3W) do(ctx context.Context, url string, hdr http.Header, in interface{}) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+
+	if err != nil {
+		return false, redactErr(err)
+	}
+
+	for k, v := range hdr {
+		req.Header[k] = v
 	}
 
-	req, err := http.NewRequest("GET", url+"?"+vals.Encode(), nil)
-	req.Header.Add("Accept", "application/json")
+	req.Header.Set("User-Agent", w.ua)
+
+	for k, v := range w.headers {
+		req.Header[k] = v
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	w.logger.LogRequest(req.Method, redactKey(url))
+	start := time.Now()
 
 	resp, err := w.client.Do(req)
 
 	if err != nil {
-		return nil, err
+		w.logger.LogResponse(0, time.Since(start))
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		return true, redactErr(err)
 	}
 
-	err = json.NewDecoder(resp.Body).Decode(in)
+	defer closeBody(resp)
+
+	w.logger.LogResponse(resp.StatusCode, time.Since(start))
+	w.rate.update(resp.Header)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return retryableStatus(resp.StatusCode), newStatusError(resp, errorBody(resp))
+	}
+
+	rd, err := decodedBody(resp)
+
+	if err != nil {
+		return true, err
+	}
+
+	defer rd.Close()
+
+	if err := checkContentType(resp, rd); err != nil {
+		return false, err
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(rd, w.maxBody+1))
 
+	if err != nil {
+		return true, err
+	}
+
+	if int64(len(body)) > w.maxBody {
+		return false, fmt.Errorf("%w: limit is %d bytes", ErrResponseTooLarge, w.maxBody)
+	}
+
+	if apiErr := parseAPIError(body); apiErr != nil {
+		return false, apiErr
+	}
+
+	return false, json.Unmarshal(body, in)
+}
+
+// closeBody drains any unread part of the response body, up to maxDrain bytes, and closes it so the
+// connection can be reused for the next request
+func closeBody(resp *http.Response) {
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxDrain))
+	resp.Body.Close()
+}
+
+// decodedBody returns the response body, transparently decompressing it if the server sent it gzip
+// encoded. A body marked as gzip that doesn't start with the gzip magic number, such as an empty
+// one, is returned raw. Closing the returned reader doesn't close the response body.
+func decodedBody(resp *http.Response) (io.ReadCloser, error) {
+	if !gzipEncoded(resp) {
+		return ioutil.NopCloser(resp.Body), nil
+	}
+
+	br := bufio.NewReader(resp.Body)
+	if magic, err := br.Peek(2); err != nil || magic[0] != 0x1f || magic[1] != 0x8b {
+		return ioutil.NopCloser(br), nil
+	}
+
+	zr, err := gzip.NewReader(br)
 	if err != nil {
 		return nil, err
 	}
 
-	return in, nil
+	return zr, nil
+}
+
+// errorBody reads up to maxErrorBody bytes of a non-2xx response, decompressed if it's gzip encoded.
+// Proxies often send an empty or plain body with a gzip Content-Encoding, and such a body is kept as
+// sent so the StatusError can still be built from it.
+func errorBody(resp *http.Response) []byte {
+	raw, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+
+	if !gzipEncoded(resp) {
+		return raw
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return raw
+	}
+	defer zr.Close()
+
+	// A body cut short by maxErrorBody decompresses as far as it goes
+	body, _ := ioutil.ReadAll(io.LimitReader(zr, maxErrorBody))
+	if len(body) == 0 {
+		return raw
+	}
+
+	return body
+}
+
+func gzipEncoded(resp *http.Response) bool {
+	return strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip")
+}
+
+// checkContentType returns an error wrapping ErrNotJSON, with the content type and the start of the
+// body rd, if the response isn't JSON, e.g. an HTML error page from a proxy. Parameters such as the
+// charset are ignored, as is a missing Content-Type, and JSON based types like
+// application/geo+json are accepted.
+func checkContentType(resp *http.Response, rd io.Reader) error {
+	ct := resp.Header.Get("Content-Type")
+	if ct == "" {
+		return nil
+	}
+
+	mt, _, err := mime.ParseMediaType(ct)
+	if err == nil && (mt == "application/json" || strings.HasSuffix(mt, "+json")) {
+		return nil
+	}
+
+	snippet, _ := ioutil.ReadAll(io.LimitReader(rd, maxSnippet))
+
+	return fmt.Errorf("%w %s: %q", ErrNotJSON, ct, snippet)
 }
@@ -0,0 +1,31 @@
+package w3w
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBadRequestURL(t *testing.T) {
+	w, err := New("APIKEY")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// WithEndpoint rejects this up front, so set it directly to reach the request building in do
+	w.endpoint = "http://w3w.example.com/\x7f"
+
+	pos, err := w.Words(What3Words{"index", "home", "raft"}, nil)
+	if err == nil {
+		t.Fatalf("Words() = %v, want an error", pos)
+	}
+
+	if strings.Contains(err.Error(), "APIKEY") {
+		t.Errorf("Words() error %q leaks the API key", err)
+	}
+}
+
+func TestEndpointControlCharacters(t *testing.T) {
+	if _, err := New("APIKEY", WithEndpoint("http://w3w.example.com/\x7f")); err == nil {
+		t.Error("New() with a control character in the endpoint succeeded, want an error")
+	}
+}
@@ -0,0 +1,767 @@
+package w3w_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/devork/w3w"
+	"github.com/devork/w3w/w3wtest"
+)
+
+// recordQueries makes s record the query of each request it serves, returning a func giving those
+// recorded so far in the order they arrived
+func recordQueries(s *w3wtest.Server) func() []url.Values {
+	var (
+		mu      sync.Mutex
+		queries []url.Values
+	)
+
+	next := s.Config.Handler
+	s.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries = append(queries, r.URL.Query())
+		mu.Unlock()
+
+		next.ServeHTTP(rw, r)
+	})
+
+	return func() []url.Values {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return append([]url.Values(nil), queries...)
+	}
+}
+
+// gzipped compresses s as a gzip encoded response body
+func gzipped(s string) string {
+	var b bytes.Buffer
+
+	zw := gzip.NewWriter(&b)
+	zw.Write([]byte(s))
+	zw.Close()
+
+	return b.String()
+}
+
+func TestGzipResponses(t *testing.T) {
+	const apiError = `{"error":{"code":"InvalidKey","message":"Invalid API key"}}`
+
+	tests := []struct {
+		name   string
+		status int
+		header map[string]string
+		body   string
+
+		wantErr    error
+		wantStatus int
+		wantCode   string
+		wantCat    w3w.ErrorCategory
+	}{
+		{"compressed", 200, nil, gzipped(w3wtest.WordsBody), nil, 0, "", w3w.CategoryNone},
+		{"uncompressed despite the header", 200, nil, w3wtest.WordsBody, nil, 0, "", w3w.CategoryNone},
+		{"empty 503", 503, nil, "", nil, 503, "", w3w.CategoryTransient},
+		{"empty 429", 429, map[string]string{"Retry-After": "3"}, "", w3w.ErrRateLimited, 429, "", w3w.CategoryTransient},
+		{"compressed 401", 401, nil, gzipped(apiError), w3w.ErrUnauthorized, 401, "InvalidKey", w3w.CategoryAuth},
+		{"uncompressed 401", 401, nil, apiError, w3w.ErrUnauthorized, 401, "InvalidKey", w3w.CategoryAuth},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				rw.Header().Set("Content-Type", "application/json")
+				rw.Header().Set("Content-Encoding", "gzip")
+				for k, v := range tt.header {
+					rw.Header().Set(k, v)
+				}
+				rw.WriteHeader(tt.status)
+				rw.Write([]byte(tt.body))
+			}))
+			defer ts.Close()
+
+			w, err := w3w.New(w3wtest.APIKey, w3w.WithEndpoint(ts.URL))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pos, err := w.Words(w3w.What3Words{"prom", "cape", "pump"}, nil)
+
+			if got := w3w.Category(err); got != tt.wantCat {
+				t.Errorf("Category(%v) = %v, want %v", err, got, tt.wantCat)
+			}
+
+			if tt.wantStatus == 0 {
+				if err != nil || pos.Words != (w3w.What3Words{"prom", "cape", "pump"}) {
+					t.Errorf("Words() = %v, %v, want prom.cape.pump", pos, err)
+				}
+				return
+			}
+
+			var se *w3w.StatusError
+			if !errors.As(err, &se) {
+				t.Fatalf("Words() error = %#v, want a *StatusError", err)
+			}
+
+			if se.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", se.StatusCode, tt.wantStatus)
+			}
+
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Words() error = %v, want it to match %v", err, tt.wantErr)
+			}
+
+			if tt.wantCode != "" && (se.API == nil || se.API.Code != tt.wantCode) {
+				t.Errorf("API = %v, want code %s", se.API, tt.wantCode)
+			}
+
+			if tt.status == 429 && se.RetryAfter != 3*time.Second {
+				t.Errorf("RetryAfter = %v, want 3s", se.RetryAfter)
+			}
+		})
+	}
+}
+
+func TestConnectionReuse(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"success", http.StatusOK, w3wtest.WordsBody},
+		// Larger than the StatusError keeps but within maxDrain, so the connection can only be reused
+		// if closeBody drains it. Recent Go releases also drain on Close, but older ones don't.
+		{"error body beyond what's kept", http.StatusBadRequest, strings.Repeat("x", 16<<10)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var conns int32
+
+			ts := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				rw.Header().Set("Content-Type", "application/json")
+				rw.WriteHeader(tt.status)
+				rw.Write([]byte(tt.body))
+			}))
+			ts.Config.ConnState = func(c net.Conn, state http.ConnState) {
+				if state == http.StateNew {
+					atomic.AddInt32(&conns, 1)
+				}
+			}
+			ts.Start()
+			defer ts.Close()
+
+			w, err := w3w.New(w3wtest.APIKey, w3w.WithEndpoint(ts.URL))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 0; i < 10; i++ {
+				w.Words(w3w.What3Words{"prom", "cape", "pump"}, nil)
+			}
+
+			if n := atomic.LoadInt32(&conns); n != 1 {
+				t.Errorf("10 sequential calls opened %d connections, want 1", n)
+			}
+		})
+	}
+}
+
+func TestLanguagesByCode(t *testing.T) {
+	langs := &w3w.Languages{Languages: []w3w.Language{{Code: "de", Name: "Deutsch"}, {Code: "en", Name: "English"}}}
+
+	tests := []struct {
+		code string
+		want string
+		ok   bool
+	}{
+		{"en", "English", true},
+		{"DE", "Deutsch", true},
+		{"fr", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			lang, ok := langs.ByCode(tt.code)
+
+			if ok != tt.ok || (ok && lang.Name != tt.want) || (!ok && lang != nil) {
+				t.Errorf("ByCode(%q) = %v, %t, want %q, %t", tt.code, lang, ok, tt.want, tt.ok)
+			}
+		})
+	}
+
+	if got := langs.Codes(); len(got) != 2 || got[0] != "de" || got[1] != "en" {
+		t.Errorf("Codes() = %v, want [de en]", got)
+	}
+}
+
+// TestConcurrentDefaults is run under -race to check calls with nil options don't race on the
+// client defaults
+func TestConcurrentDefaults(t *testing.T) {
+	s := w3wtest.NewServer(w3w.WithDefaults(&w3w.Options{Lang: "de", Corners: true}))
+	defer s.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if _, err := s.W3W.Words(w3w.What3Words{"prom", "cape", "pump"}, nil); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDefaultsIsolated(t *testing.T) {
+	s := w3wtest.NewServer()
+	defer s.Close()
+
+	queries := recordQueries(s)
+
+	shared := &w3w.Options{Lang: "de"}
+
+	first, err := w3w.New(w3wtest.APIKey, w3w.WithEndpoint(s.URL), shared)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := w3w.New(w3wtest.APIKey, w3w.WithEndpoint(s.URL), shared)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plain, err := w3w.New(w3wtest.APIKey, w3w.WithEndpoint(s.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Changing the options given to New, or one client's defaults, must leave the others alone
+	shared.Lang = "es"
+	second = second.WithDefaultLang("fr")
+
+	for _, w := range []*w3w.W3W{first, second, plain} {
+		if _, err := w.Words(w3w.What3Words{"prom", "cape", "pump"}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{"de", "fr", "en"}
+	for i, q := range queries() {
+		if got := q.Get("lang"); got != want[i] {
+			t.Errorf("client %d sent lang=%s, want %s", i+1, got, want[i])
+		}
+	}
+}
+
+func TestNewWords(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b, c string
+		wantErr bool
+	}{
+		{"valid", "index", "home", "raft", false},
+		{"empty middle word", "index", "", "raft", true},
+		{"empty first word", "", "home", "raft", true},
+		{"dot", "index", "ho.me", "raft", true},
+		{"comma", "index", "home", "ra,ft", true},
+		{"slash", "in/dex", "home", "raft", true},
+		{"space", "index", "ho me", "raft", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			words, err := w3w.NewWords(tt.a, tt.b, tt.c)
+
+			if tt.wantErr {
+				if !errors.Is(err, w3w.ErrInvalidWords) || words != (w3w.What3Words{}) {
+					t.Errorf("NewWords() = %v, %v, want empty words and ErrInvalidWords", words, err)
+				}
+				return
+			}
+
+			if err != nil || words != (w3w.What3Words{tt.a, tt.b, tt.c}) {
+				t.Errorf("NewWords() = %v, %v, want %s.%s.%s", words, err, tt.a, tt.b, tt.c)
+			}
+		})
+	}
+}
+
+func TestWordsJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		words w3w.What3Words
+		json  string
+	}{
+		{"words", w3w.What3Words{"index", "home", "raft"}, `"index.home.raft"`},
+		{"zero", w3w.What3Words{}, `""`},
+		{"empty middle word", w3w.What3Words{"a", "", "c"}, `"a..c"`},
+		{"only the last word", w3w.What3Words{"", "", "c"}, `"..c"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.words)
+			if err != nil || string(data) != tt.json {
+				t.Fatalf("Marshal() = %s, %v, want %s", data, err, tt.json)
+			}
+
+			var got w3w.What3Words
+			if err := json.Unmarshal(data, &got); err != nil || got != tt.words {
+				t.Errorf("Unmarshal(%s) = %q, %v, want %q", data, got, err, tt.words)
+			}
+		})
+	}
+}
+
+func TestWordsUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		json    string
+		want    w3w.What3Words
+		wantErr bool
+	}{
+		{`["index","home","raft"]`, w3w.What3Words{"index", "home", "raft"}, false},
+		{`"index.home.raft"`, w3w.What3Words{"index", "home", "raft"}, false},
+		{`"///index.home.raft"`, w3w.What3Words{"index", "home", "raft"}, false},
+		{`["a","","c"]`, w3w.What3Words{"a", "", "c"}, false},
+		{`""`, w3w.What3Words{}, false},
+		{`"index.home"`, w3w.What3Words{}, true},
+		{`"index.home.raft.extra"`, w3w.What3Words{}, true},
+		{`42`, w3w.What3Words{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.json, func(t *testing.T) {
+			var got w3w.What3Words
+			err := json.Unmarshal([]byte(tt.json), &got)
+
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("Unmarshal() = %q, %v, want %q with error %t", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPositionJSONRoundTrip(t *testing.T) {
+	for _, pos := range []w3w.Position{{}, {Words: w3w.What3Words{"prom", "cape", "pump"}, Position: &w3w.LatLng{51.484463, -0.195405}}} {
+		data, err := json.Marshal(pos)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got w3w.Position
+		if err := json.Unmarshal(data, &got); err != nil || got.Words != pos.Words {
+			t.Errorf("Unmarshal(%s) = %v, %v, want words %q", data, got, err, pos.Words)
+		}
+	}
+}
+
+func TestOverrideDefaults(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        *w3w.Options
+		wantLang    string
+		wantCorners string
+		wantErr     error
+	}{
+		{"defaults", nil, "en", "true", nil},
+		{"language only", &w3w.Options{Lang: "de"}, "de", "true", nil},
+		{"no corners", &w3w.Options{Lang: "de", NoCorners: true}, "de", "", nil},
+		{"corners and no corners", &w3w.Options{Corners: true, NoCorners: true}, "", "", w3w.ErrInvalidOptions},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := w3wtest.NewServer(&w3w.Options{Lang: "en", Corners: true})
+			defer s.Close()
+
+			queries := recordQueries(s)
+
+			_, err := s.W3W.Position(w3w.LatLng{51.484463, -0.195405}, tt.opts)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Position() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			q := queries()[0]
+			if q.Get("lang") != tt.wantLang || q.Get("corners") != tt.wantCorners {
+				t.Errorf("sent lang=%q corners=%q, want lang=%q corners=%q", q.Get("lang"), q.Get("corners"), tt.wantLang, tt.wantCorners)
+			}
+		})
+	}
+}
+
+func TestOverrideNoCache(t *testing.T) {
+	s := w3wtest.NewServer(w3w.WithCache(8), &w3w.Options{NoCache: true})
+	defer s.Close()
+
+	queries := recordQueries(s)
+	words := w3w.What3Words{"prom", "cape", "pump"}
+
+	for _, opts := range []*w3w.Options{nil, nil, {UseCache: true}, {UseCache: true}} {
+		if _, err := s.W3W.Words(words, opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Both calls with the defaults bypass the cache, and only the first with UseCache misses it
+	if n := len(queries()); n != 3 {
+		t.Errorf("made %d requests, want 3", n)
+	}
+}
+
+func TestIsPossibleAddress(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"index.home.raft", true},
+		{"///index.home.raft", true},
+		{"インデックス。ホーム。ラフト", true},
+		{"index.home", false},
+		{"index", false},
+		{"", false},
+		{"index.home.raft.extra", false},
+		{"index.h0me.raft", false},
+		{"123.456.789", false},
+		{"index home raft", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := w3w.IsPossibleAddress(tt.input); got != tt.want {
+				t.Errorf("IsPossibleAddress(%q) = %t, want %t", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAutoNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		words    w3w.What3Words
+		plainErr error
+	}{
+		// Capitals are lowercased for the request either way, so only whitespace needs the option
+		{"capitals", w3w.What3Words{"Index", "HOME", "Raft"}, nil},
+		{"whitespace", w3w.What3Words{" index", "home ", "\traft\n"}, w3w.ErrInvalidWords},
+		{"both", w3w.What3Words{"Index", " Home ", "Raft"}, w3w.ErrInvalidWords},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := w3wtest.NewServer(w3w.WithAutoNormalize())
+			defer s.Close()
+
+			queries := recordQueries(s)
+
+			if _, err := s.W3W.Words(tt.words, nil); err != nil {
+				t.Fatalf("Words() with WithAutoNormalize = %v, want success", err)
+			}
+
+			if got := queries()[0].Get("string"); got != "index.home.raft" {
+				t.Errorf("sent string=%q, want index.home.raft", got)
+			}
+
+			plain := w3wtest.NewServer()
+			defer plain.Close()
+
+			if _, err := plain.W3W.Words(tt.words, nil); !errors.Is(err, tt.plainErr) {
+				t.Errorf("Words() without WithAutoNormalize = %v, want %v", err, tt.plainErr)
+			}
+		})
+	}
+}
+
+func TestBackfillLanguage(t *testing.T) {
+	const noLanguage = `{"type":"3 words","words":["prom","cape","pump"],"position":[51.484463,-0.195405]}`
+
+	tests := []struct {
+		name     string
+		body     string
+		defaults *w3w.Options
+		opts     *w3w.Options
+		want     string
+	}{
+		{"requested language", noLanguage, nil, &w3w.Options{Lang: "de"}, "de"},
+		{"default language", noLanguage, &w3w.Options{Lang: "fr"}, nil, "fr"},
+		{"call overrides default", noLanguage, &w3w.Options{Lang: "fr"}, &w3w.Options{Lang: "de"}, "de"},
+		{"server language kept", w3wtest.WordsBody, nil, &w3w.Options{Lang: "de"}, "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []w3w.Option
+			if tt.defaults != nil {
+				opts = append(opts, tt.defaults)
+			}
+
+			s := w3wtest.NewServer(opts...)
+			defer s.Close()
+
+			s.SetFixture("/w3w", w3wtest.Fixture{Status: http.StatusOK, Body: tt.body})
+			s.SetFixture("/position", w3wtest.Fixture{Status: http.StatusOK, Body: tt.body})
+
+			pos, err := s.W3W.Words(w3w.What3Words{"prom", "cape", "pump"}, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if pos.Language != tt.want {
+				t.Errorf("Words() language = %q, want %q", pos.Language, tt.want)
+			}
+
+			pos, err = s.W3W.Position(w3w.LatLng{51.484463, -0.195405}, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if pos.Language != tt.want {
+				t.Errorf("Position() language = %q, want %q", pos.Language, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocale(t *testing.T) {
+	tests := []struct {
+		name       string
+		defaults   *w3w.Options
+		opts       *w3w.Options
+		wantLang   string
+		wantLocale string
+	}{
+		{"no locale", nil, nil, "en", ""},
+		{"locale", nil, &w3w.Options{Lang: "zh", Locale: "zh_tr"}, "zh", "zh_tr"},
+		{"default locale", &w3w.Options{Lang: "zh", Locale: "zh_si"}, nil, "zh", "zh_si"},
+		{"call overrides default", &w3w.Options{Lang: "zh", Locale: "zh_si"}, &w3w.Options{Locale: "zh_tr"}, "zh", "zh_tr"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []w3w.Option
+			if tt.defaults != nil {
+				opts = append(opts, tt.defaults)
+			}
+
+			s := w3wtest.NewServer(opts...)
+			defer s.Close()
+
+			queries := recordQueries(s)
+
+			if _, err := s.W3W.Words(w3w.What3Words{"prom", "cape", "pump"}, tt.opts); err != nil {
+				t.Fatal(err)
+			}
+
+			q := queries()[0]
+			if _, ok := q["locale"]; ok != (tt.wantLocale != "") {
+				t.Errorf("locale sent = %t, want %t", ok, tt.wantLocale != "")
+			}
+
+			if q.Get("lang") != tt.wantLang || q.Get("locale") != tt.wantLocale {
+				t.Errorf("sent lang=%q locale=%q, want lang=%q locale=%q", q.Get("lang"), q.Get("locale"), tt.wantLang, tt.wantLocale)
+			}
+		})
+	}
+}
+
+func TestLocaleCached(t *testing.T) {
+	s := w3wtest.NewServer(w3w.WithCache(8))
+	defer s.Close()
+
+	queries := recordQueries(s)
+	words := w3w.What3Words{"prom", "cape", "pump"}
+
+	for _, locale := range []string{"zh_tr", "zh_si", "zh_tr"} {
+		if _, err := s.W3W.Words(words, &w3w.Options{Lang: "zh", Locale: locale}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Each locale is cached separately, so only the repeated zh_tr is served from the cache
+	if n := len(queries()); n != 2 {
+		t.Errorf("made %d requests, want 2", n)
+	}
+}
+
+func TestPositionMalformedCorners(t *testing.T) {
+	tests := []struct {
+		name    string
+		corners string
+		want    *w3w.BBox
+	}{
+		{"absent", ``, nil},
+		{"null", `,"corners":null`, nil},
+		{"object", `,"corners":{}`, nil},
+		{"empty", `,"corners":[]`, nil},
+		{"one corner", `,"corners":[[1,2]]`, nil},
+		{"null corner", `,"corners":[[1,2],null]`, nil},
+		{"string", `,"corners":"none"`, nil},
+		{"both corners", `,"corners":[[1,2],[3,4]]`, &w3w.BBox{{1, 2}, {3, 4}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"words":["prom","cape","pump"],"position":[51.484463,-0.195405]` + tt.corners + `}`
+
+			// Seed Corners so a decode that leaves a stale value in place is caught
+			pos := w3w.Position{Corners: &w3w.BBox{{9, 9}, {9, 9}}}
+			if err := json.Unmarshal([]byte(body), &pos); err != nil {
+				t.Fatalf("Unmarshal() = %v", err)
+			}
+
+			if pos.Words != (w3w.What3Words{"prom", "cape", "pump"}) {
+				t.Errorf("Words = %v, want prom.cape.pump", pos.Words)
+			}
+
+			switch {
+			case tt.want == nil && pos.Corners != nil:
+				t.Errorf("Corners = %v, want nil", *pos.Corners)
+			case tt.want != nil && (pos.Corners == nil || *pos.Corners.SW() != *tt.want.SW() || *pos.Corners.NE() != *tt.want.NE()):
+				t.Errorf("Corners = %v, want %v", pos.Corners, tt.want)
+			}
+
+			s := w3wtest.NewServer()
+			defer s.Close()
+
+			s.SetFixture("/w3w", w3wtest.Fixture{Status: http.StatusOK, Body: body})
+
+			got, err := s.W3W.Words(w3w.What3Words{"prom", "cape", "pump"}, &w3w.Options{Corners: true})
+			if err != nil {
+				t.Fatalf("Words() = %v", err)
+			}
+
+			if (got.Corners == nil) != (tt.want == nil) {
+				t.Errorf("Words() Corners = %v, want %v", got.Corners, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeoutCoversConcurrencyWait(t *testing.T) {
+	const timeout = 100 * time.Millisecond
+
+	s := w3wtest.NewServer(w3w.WithMaxConcurrency(1), w3w.WithTimeout(timeout))
+	defer s.Close()
+
+	// Stall every request until the client gives up on it, or the test ends
+	arrived := make(chan struct{}, 1)
+	release := make(chan struct{})
+	defer close(release)
+
+	s.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	})
+
+	words := w3w.What3Words{"prom", "cape", "pump"}
+
+	go s.W3W.Words(words, nil)
+	<-arrived
+
+	// The first call holds the only slot for its whole timeout, so the second times out waiting
+	start := time.Now()
+	_, err := s.W3W.Words(words, nil)
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Words() = %v, want context.DeadlineExceeded", err)
+	}
+
+	if elapsed > timeout+timeout/2 {
+		t.Errorf("Words() took %v, want at most the %v timeout", elapsed, timeout)
+	}
+}
+
+func TestRoundTripVerifyContext(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		words    w3w.What3Words
+		want     bool
+		wantErr  error
+		wantReqs int
+	}{
+		{"match", context.Background(), w3w.What3Words{"Prom", "cape", "pump"}, true, nil, 2},
+		{"mismatch", context.Background(), w3w.What3Words{"index", "home", "raft"}, false, nil, 2},
+		{"cancelled", cancelled, w3w.What3Words{"prom", "cape", "pump"}, false, context.Canceled, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := w3wtest.NewServer()
+			defer s.Close()
+
+			queries := recordQueries(s)
+
+			got, err := s.W3W.RoundTripVerifyContext(tt.ctx, tt.words, nil)
+			if got != tt.want || !errors.Is(err, tt.wantErr) {
+				t.Errorf("RoundTripVerifyContext() = %t, %v, want %t, %v", got, err, tt.want, tt.wantErr)
+			}
+
+			if n := len(queries()); n != tt.wantReqs {
+				t.Errorf("made %d requests, want %d", n, tt.wantReqs)
+			}
+		})
+	}
+}
+
+func TestWithDefaultLang(t *testing.T) {
+	tests := []struct {
+		name       string
+		clientOpts []w3w.Option
+		lang       string
+		opts       *w3w.Options
+		want       string
+	}{
+		{"lang", nil, "de", nil, "de"},
+		{"call overrides", nil, "de", &w3w.Options{Lang: "fr"}, "fr"},
+		{"empty falls back to client language", []w3w.Option{w3w.WithLanguage("fr")}, "", nil, "fr"},
+		{"empty falls back to en", nil, "", nil, "en"},
+		{"empty with client defaults", []w3w.Option{w3w.WithLanguage("fr"), &w3w.Options{Lang: "de"}}, "", nil, "fr"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := w3wtest.NewServer(tt.clientOpts...)
+			defer s.Close()
+
+			queries := recordQueries(s)
+
+			if _, err := s.W3W.WithDefaultLang(tt.lang).Words(w3w.What3Words{"prom", "cape", "pump"}, tt.opts); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := queries()[0].Get("lang"); got != tt.want {
+				t.Errorf("sent lang=%q, want %q", got, tt.want)
+			}
+		})
+	}
+}
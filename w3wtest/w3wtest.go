@@ -0,0 +1,120 @@
+// Package w3wtest provides a fake W3W server for testing code that uses the w3w client, without a
+// real API key or network access.
+package w3wtest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/devork/w3w"
+)
+
+// APIKey is the key the W3W client returned by NewServer is configured with
+const APIKey string = "w3wtest"
+
+// Canned fixture bodies served by default
+const (
+	WordsBody string = `{"type":"3 words","words":["prom","cape","pump"],` +
+		`"position":[51.484463,-0.195405],` +
+		`"corners":[[51.484449,-0.195426],[51.484476,-0.195383]],"language":"en"}`
+
+	PositionBody string = WordsBody
+
+	// V3Body is a recorded v3 convert-to-coordinates and convert-to-3wa response
+	V3Body string = `{"country":"GB","square":{"southwest":{"lng":-0.195426,"lat":51.484449},` +
+		`"northeast":{"lng":-0.195383,"lat":51.484476}},"nearestPlace":"Fulham, London",` +
+		`"coordinates":{"lng":-0.195405,"lat":51.484463},"words":"prom.cape.pump",` +
+		`"language":"en","map":"https://w3w.co/prom.cape.pump"}`
+
+	LanguagesBody string = `{"languages":[{"code":"de","name_display":"Deutsch"},` +
+		`{"code":"en","name_display":"English"},{"code":"fr","name_display":"Français"}]}`
+
+	// V3LanguagesBody is a recorded v3 available-languages response
+	V3LanguagesBody string = `{"languages":[{"nativeName":"Deutsch","code":"de","name":"German"},` +
+		`{"nativeName":"English","code":"en","name":"English"},` +
+		`{"nativeName":"Français","code":"fr","name":"French"}]}`
+)
+
+// ----------------------------------------------------------------------------
+// Fixture struct
+// ----------------------------------------------------------------------------
+
+// Fixture is the canned response served for a path
+type Fixture struct {
+	Status int
+	Body   string
+}
+
+// ----------------------------------------------------------------------------
+// Server struct
+// ----------------------------------------------------------------------------
+
+// Server is a running fake W3W server along with a W3W client pointed at it
+type Server struct {
+	*httptest.Server
+
+	// W3W is a client configured to call the fake server
+	W3W *w3w.W3W
+
+	mu       sync.Mutex
+	fixtures map[string]Fixture
+}
+
+// NewServer starts a fake W3W server answering /w3w, /position and /get-languages, as well as the
+// v3 /v3/convert-to-coordinates, /v3/convert-to-3wa and /v3/available-languages, with canned
+// fixtures. Any opts are applied to the returned client after it's pointed at the server. The
+// caller should Close the server when finished.
+func NewServer(opts ...w3w.Option) *Server {
+	s := &Server{
+		fixtures: map[string]Fixture{
+			"/w3w":           {http.StatusOK, WordsBody},
+			"/position":      {http.StatusOK, PositionBody},
+			"/get-languages": {http.StatusOK, LanguagesBody},
+
+			"/v3/convert-to-coordinates": {http.StatusOK, V3Body},
+			"/v3/convert-to-3wa":         {http.StatusOK, V3Body},
+			"/v3/available-languages":    {http.StatusOK, V3LanguagesBody},
+		},
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+
+	client, err := w3w.New(APIKey, append([]w3w.Option{w3w.WithEndpoint(s.URL)}, opts...)...)
+	if err != nil {
+		s.Close()
+		panic("w3wtest: " + err.Error())
+	}
+
+	s.W3W = client
+	return s
+}
+
+// SetFixture overrides the response for path, e.g. to serve an error payload
+//
+//	s.SetFixture("/w3w", w3wtest.Fixture{http.StatusOK, `{"error":{"code":"BadWords","message":"..."}}`})
+func (s *Server) SetFixture(path string, f Fixture) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fixtures[path] = f
+}
+
+func (s *Server) serve(rw http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	f, ok := s.fixtures[r.URL.Path]
+	s.mu.Unlock()
+
+	if !ok {
+		http.NotFound(rw, r)
+		return
+	}
+
+	if r.URL.Query().Get("key") != APIKey {
+		f = Fixture{http.StatusUnauthorized, `{"error":{"code":"InvalidKey","message":"Invalid API key"}}`}
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(f.Status)
+	rw.Write([]byte(f.Body))
+}
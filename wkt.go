@@ -0,0 +1,42 @@
+package w3w
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+// Well-Known Text
+// ----------------------------------------------------------------------------
+
+// wktPoint returns ll as a WKT coordinate pair, in WKT's "lng lat" order
+func wktPoint(ll *LatLng) string {
+	return strconv.FormatFloat(ll.Lng(), 'f', -1, 64) + " " + strconv.FormatFloat(ll.Lat(), 'f', -1, 64)
+}
+
+// WKT returns the position as a Well-Known Text point, e.g. `POINT(-0.195405 51.484463)`, or
+// `POINT EMPTY` if the position has no coordinates
+func (p *Position) WKT() string {
+	if p.Position == nil {
+		return "POINT EMPTY"
+	}
+
+	return "POINT(" + wktPoint(p.Position) + ")"
+}
+
+// WKT returns the square as a Well-Known Text polygon of the closed ring from Polygon, e.g.
+// `POLYGON((-0.1954 51.4844,-0.1953 51.4844,-0.1953 51.4845,-0.1954 51.4845,-0.1954 51.4844))`,
+// or `POLYGON EMPTY` if the box or either of its corners is missing
+func (b *BBox) WKT() string {
+	ring := b.Polygon()
+	if ring == nil {
+		return "POLYGON EMPTY"
+	}
+
+	points := make([]string, len(ring))
+	for i, ll := range ring {
+		points[i] = wktPoint(ll)
+	}
+
+	return "POLYGON((" + strings.Join(points, ",") + "))"
+}
@@ -0,0 +1,52 @@
+package w3w_test
+
+import (
+	"testing"
+
+	"github.com/devork/w3w"
+)
+
+func TestPositionWKT(t *testing.T) {
+	tests := []struct {
+		name string
+		pos  *w3w.Position
+		want string
+	}{
+		{"point", &w3w.Position{Position: &w3w.LatLng{51.484463, -0.195405}}, "POINT(-0.195405 51.484463)"},
+		{"southern hemisphere", &w3w.Position{Position: &w3w.LatLng{-33.8688, 151.2093}}, "POINT(151.2093 -33.8688)"},
+		{"whole degrees", &w3w.Position{Position: &w3w.LatLng{10, -20}}, "POINT(-20 10)"},
+		{"no coordinates", &w3w.Position{}, "POINT EMPTY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.pos.WKT(); got != tt.want {
+				t.Errorf("WKT() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBBoxWKT(t *testing.T) {
+	tests := []struct {
+		name string
+		box  *w3w.BBox
+		want string
+	}{
+		{
+			"square",
+			&w3w.BBox{{51.484449, -0.195426}, {51.484476, -0.195383}},
+			"POLYGON((-0.195426 51.484449,-0.195383 51.484449,-0.195383 51.484476,-0.195426 51.484476,-0.195426 51.484449))",
+		},
+		{"nil box", nil, "POLYGON EMPTY"},
+		{"missing corner", &w3w.BBox{{51.484449, -0.195426}, nil}, "POLYGON EMPTY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.box.WKT(); got != tt.want {
+				t.Errorf("WKT() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}